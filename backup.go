@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// backupTimeFormat stamps backup file names so that they sort in the order they were taken.
+const backupTimeFormat = "2006-01-02T150405"
+
+// BackupConfig controls the copy of the sprint board that is saved before each close.
+type BackupConfig struct {
+	// Directory is where backups are written. Defaults to the working directory.
+	Directory string `json:"directory"`
+
+	// Keep is the number of backups to retain. Older backups are deleted after each close. Every
+	// backup is kept if it is zero.
+	Keep int `json:"keep"`
+}
+
+// Backup is the complete state of a board at one moment, as Trello reported it.
+type Backup struct {
+	Taken   time.Time         `json:"taken"`
+	Board   json.RawMessage   `json:"board"`
+	Actions []json.RawMessage `json:"actions"`
+}
+
+// takeBackup exports a board and its history.
+func takeBackup(conn Connection, boardID string) (*Backup, error) {
+	b := &Backup{Taken: time.Now()}
+
+	board, err := conn.ExportBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+	b.Board = board
+
+	actions, err := conn.ExportBoardActions(boardID)
+	if err != nil {
+		return nil, err
+	}
+	b.Actions = actions
+
+	return b, nil
+}
+
+// writeBackup saves a backup to a timestamped file, then deletes the oldest backups beyond the
+// configured retention. It returns the path that was written.
+func writeBackup(config BackupConfig, b *Backup) (string, error) {
+	dir := config.Directory
+	if dir == "" {
+		dir = "."
+	}
+
+	path := filepath.Join(dir, "sprint-backup-"+b.Taken.Format(backupTimeFormat)+".json")
+	outf, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	enc := json.NewEncoder(outf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		outf.Close()
+		return "", err
+	}
+
+	if err := outf.Close(); err != nil {
+		return "", err
+	}
+
+	if config.Keep <= 0 {
+		return path, nil
+	}
+
+	existing, err := filepath.Glob(filepath.Join(dir, "sprint-backup-*.json"))
+	if err != nil {
+		return path, err
+	}
+	sort.Strings(existing)
+
+	for len(existing) > config.Keep {
+		log.WithField("path", existing[0]).Debug("Deleting old backup")
+		if err := os.Remove(existing[0]); err != nil {
+			return path, err
+		}
+		existing = existing[1:]
+	}
+
+	return path, nil
+}
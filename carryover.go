@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// carryOverListName is the name of the list on the archive board that records slipped cards.
+const carryOverListName = "Carried Over"
+
+// defaultCounterField is the custom field that the stale command reads when no counter_field is set.
+const defaultCounterField = "Sprints Carried"
+
+// CarryOver configures the handling of unfinished cards when a sprint is closed.
+type CarryOver struct {
+	// Lists identifies the lists on the sprint board that hold unfinished work.
+	Lists []string `json:"lists"`
+
+	// Label, if set, is applied to each unfinished card.
+	Label string `json:"label"`
+
+	// CounterField, if set, names a numeric custom field that counts the sprint closes each unfinished
+	// card has survived.
+	CounterField string `json:"counter_field"`
+
+	// TargetList, if set, identifies a list on the sprint board that unfinished cards are moved to.
+	TargetList string `json:"target_list"`
+}
+
+// carryOverPlan holds the lists that a carry-over will touch, located before anything is changed.
+type carryOverPlan struct {
+	config  CarryOver
+	boardID string
+	sources []*List
+	target  *List
+}
+
+// planCarryOver locates each list that the configured carry-over needs on the sprint board.
+func planCarryOver(conn Connection, config CarryOver, boardID string) (*carryOverPlan, error) {
+	plan := &carryOverPlan{config: config, boardID: boardID}
+
+	for _, ref := range config.Lists {
+		list, err := conn.FindList(ref, boardID)
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithFields(log.Fields{
+			"list id":   list.ID,
+			"list name": list.Name,
+		}).Debug("Carry-over list located.")
+
+		plan.sources = append(plan.sources, list)
+	}
+
+	if len(plan.sources) > 0 {
+		if err := checkCounterField(conn, boardID, config.CounterField); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.TargetList != "" {
+		target, err := conn.FindList(config.TargetList, boardID)
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithField("list id", target.ID).Debug("Carry-over target list located.")
+
+		plan.target = target
+	}
+
+	return plan, nil
+}
+
+// execute tags each unfinished card and moves it to the target list, if one is configured. It
+// returns every card that slipped out of the sprint.
+func (plan *carryOverPlan) execute(conn Connection) ([]Card, error) {
+	var labelID, fieldID string
+	var err error
+
+	if len(plan.sources) == 0 {
+		return nil, nil
+	}
+
+	if plan.config.Label != "" {
+		labelID, err = conn.EnsureLabel(plan.boardID, plan.config.Label, "orange")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if plan.config.CounterField != "" {
+		fieldID, err = conn.EnsureNumberField(plan.boardID, plan.config.CounterField)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var slipped []Card
+	for _, list := range plan.sources {
+		cards, err := conn.GetCards(list.ID)
+		if err != nil {
+			return slipped, err
+		}
+
+		for _, card := range cards {
+			log.WithFields(log.Fields{
+				"card id":   card.ID,
+				"card name": card.Name,
+			}).Debug("Carrying over card")
+
+			if labelID != "" && !containsString(card.LabelIDs, labelID) {
+				if err := conn.AddLabel(card.ID, labelID); err != nil {
+					return slipped, err
+				}
+			}
+
+			if fieldID != "" {
+				if err := conn.SetNumberField(card.ID, fieldID, card.NumberField(fieldID)+1); err != nil {
+					return slipped, err
+				}
+			}
+
+			if plan.target != nil && card.ListID != plan.target.ID {
+				if err := conn.MoveCard(card.ID, plan.target.ID); err != nil {
+					return slipped, err
+				}
+			}
+
+			slipped = append(slipped, card)
+		}
+	}
+
+	return slipped, nil
+}
+
+// recordCarryOver adds a list to the archive board with one card linking to each slipped card.
+func recordCarryOver(conn Connection, archiveBoardID string, position int, slipped []Card) error {
+	if len(slipped) == 0 {
+		return nil
+	}
+
+	listID, err := conn.AddList(carryOverListName, archiveBoardID, float64(position))
+	if err != nil {
+		return err
+	}
+
+	for _, card := range slipped {
+		_, err := conn.AddCard(listID, card.Name, fmt.Sprintf("Carried over from this sprint: %s", card.ShortURL))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkCounterField makes sure that the counter field exists on the board or can be created there.
+func checkCounterField(conn Connection, boardID, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	fieldID, err := conn.FindNumberField(boardID, name)
+	if err != nil || fieldID != "" {
+		return err
+	}
+
+	enabled, err := conn.HasCustomFields(boardID)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		return fmt.Errorf("Enable the Custom Fields power-up on the sprint board to count carried over cards in [%s], "+
+			"or remove counter_field.", name)
+	}
+	return nil
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, each := range haystack {
+		if each == needle {
+			return true
+		}
+	}
+	return false
+}
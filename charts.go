@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// historyActionTypes are the board actions that change which list a card is in.
+var historyActionTypes = []string{
+	"createCard",
+	"copyCard",
+	"convertToCardFromCheckItem",
+	"moveCardToBoard",
+	"moveCardFromBoard",
+	"deleteCard",
+	"updateCard:idList",
+	"updateCard:closed",
+}
+
+// Chart dimensions, in pixels.
+const (
+	chartWidth  = 720
+	chartHeight = 400
+	chartLeft   = 50
+	chartRight  = 170
+	chartTop    = 40
+	chartBottom = 50
+)
+
+// chartColors are assigned to lists in order.
+var chartColors = []string{
+	"#61bd4f", "#0079bf", "#f2d600", "#ff9f1a", "#eb5a46",
+	"#c377e0", "#00c2e0", "#51e898", "#ff78cb", "#344563",
+}
+
+// ListHistory is the number of cards in each list of a board at the end of each day of a sprint.
+type ListHistory struct {
+	Days   []time.Time
+	Lists  []List
+	Counts []map[string]int
+}
+
+// Count returns the number of cards that were in a list at the end of a day.
+func (h *ListHistory) Count(day int, listID string) int {
+	return h.Counts[day][listID]
+}
+
+type byPosition []List
+
+func (s byPosition) Len() int           { return len(s) }
+func (s byPosition) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byPosition) Less(i, j int) bool { return s[i].Position < s[j].Position }
+
+// rebuildListHistory reconstructs daily list membership on a board for the days up to and including
+// end. It starts from the board as it is now and undoes its actions, newest first, so it must run
+// before any lists are moved off of the board.
+func rebuildListHistory(conn Connection, boardID string, end time.Time, days int) (*ListHistory, error) {
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	start := end.AddDate(0, 0, 1-days)
+
+	lists, err := conn.GetLists(boardID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Stable(byPosition(lists))
+
+	cards, err := conn.GetBoardCards(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := conn.GetBoardActions(boardID, start, historyActionTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"card count":   len(cards),
+		"action count": len(actions),
+	}).Debug("Rebuilding list history")
+
+	state := make(map[string]string, len(cards))
+	for _, card := range cards {
+		state[card.ID] = card.ListID
+	}
+
+	h := &ListHistory{
+		Days:   make([]time.Time, days),
+		Counts: make([]map[string]int, days),
+	}
+
+	now := time.Now()
+	next := 0
+	for day := days - 1; day >= 0; day-- {
+		h.Days[day] = start.AddDate(0, 0, day)
+
+		boundary := start.AddDate(0, 0, day+1)
+		if boundary.After(now) {
+			boundary = now
+		}
+
+		for next < len(actions) && actions[next].Date.After(boundary) {
+			undoAction(state, actions[next])
+			next++
+		}
+
+		counts := make(map[string]int)
+		for _, listID := range state {
+			counts[listID]++
+		}
+		h.Counts[day] = counts
+	}
+
+	// Keep the open lists, plus any closed list that held cards during the sprint.
+	for _, list := range lists {
+		used := false
+		for _, counts := range h.Counts {
+			if counts[list.ID] > 0 {
+				used = true
+				break
+			}
+		}
+
+		if !list.Closed || used {
+			h.Lists = append(h.Lists, list)
+		}
+	}
+
+	return h, nil
+}
+
+// undoAction reverses the effect of one action on a map of card IDs to list IDs.
+func undoAction(state map[string]string, action Action) {
+	cardID := action.Data.Card.ID
+
+	switch action.Type {
+	case "createCard", "copyCard", "convertToCardFromCheckItem", "moveCardToBoard":
+		delete(state, cardID)
+	case "moveCardFromBoard", "deleteCard":
+		if action.Data.List != nil {
+			state[cardID] = action.Data.List.ID
+		}
+	case "updateCard":
+		switch {
+		case action.Data.ListBefore != nil:
+			state[cardID] = action.Data.ListBefore.ID
+		case action.Data.Card.Closed && action.Data.List != nil:
+			state[cardID] = action.Data.List.ID
+		case !action.Data.Card.Closed:
+			delete(state, cardID)
+		}
+	}
+}
+
+// writeCharts renders a burndown chart and a cumulative flow diagram next to the sprint report.
+// It returns the paths of the files that it wrote.
+func writeCharts(dir, name string, h *ListHistory, doneRef string) ([]string, error) {
+	done, err := ParseRef(doneRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+
+	charts := []struct {
+		suffix string
+		render func(io.Writer, string, *ListHistory, Ref) error
+	}{
+		{"burndown", renderBurndown},
+		{"flow", renderCumulativeFlow},
+	}
+
+	var paths []string
+	for _, chart := range charts {
+		path := filepath.Join(dir, fmt.Sprintf("%s %s.svg", name, chart.suffix))
+
+		outf, err := os.Create(path)
+		if err != nil {
+			return paths, err
+		}
+
+		if err := chart.render(outf, name, h, done); err != nil {
+			outf.Close()
+			return paths, err
+		}
+
+		if err := outf.Close(); err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// chartFrame holds the scales shared by both charts.
+type chartFrame struct {
+	days int
+	max  int
+}
+
+func (f chartFrame) x(day int) float64 {
+	plot := float64(chartWidth - chartLeft - chartRight)
+	if f.days < 2 {
+		return chartLeft + plot/2
+	}
+	return chartLeft + plot*float64(day)/float64(f.days-1)
+}
+
+func (f chartFrame) y(value float64) float64 {
+	plot := float64(chartHeight - chartTop - chartBottom)
+	return chartTop + plot - plot*value/float64(f.max)
+}
+
+// begin writes the SVG header, title and axes.
+func (f chartFrame) begin(w io.Writer, title string, h *ListHistory) {
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="11">`+"\n",
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	fmt.Fprintf(w, `<text x="%d" y="%d" font-size="15" font-weight="bold">%s</text>`+"\n", chartLeft, chartTop-16, svgEscape(title))
+
+	bottom := f.y(0)
+	fmt.Fprintf(w, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#5e6c84"/>`+"\n", chartLeft, bottom, chartWidth-chartRight, bottom)
+	fmt.Fprintf(w, `<line x1="%d" y1="%d" x2="%d" y2="%.1f" stroke="#5e6c84"/>`+"\n", chartLeft, chartTop, chartLeft, bottom)
+
+	step := int(math.Ceil(float64(f.max) / 5))
+	for v := 0; v <= f.max; v += step {
+		y := f.y(float64(v))
+		fmt.Fprintf(w, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#dfe1e6"/>`+"\n", chartLeft+1, y, chartWidth-chartRight, y)
+		fmt.Fprintf(w, `<text x="%d" y="%.1f" text-anchor="end">%d</text>`+"\n", chartLeft-6, y+4, v)
+	}
+
+	every := int(math.Ceil(float64(f.days) / 8))
+	if every < 1 {
+		every = 1
+	}
+	for day := 0; day < f.days; day += every {
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", f.x(day), bottom+18, h.Days[day].Format("Jan 2"))
+	}
+}
+
+// legend writes one legend entry at the right of the chart.
+func (f chartFrame) legend(w io.Writer, i int, color, label string, dashed bool) {
+	x := chartWidth - chartRight + 16
+	y := chartTop + 18*i
+
+	if dashed {
+		fmt.Fprintf(w, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2" stroke-dasharray="4 3"/>`+"\n", x, y+6, x+12, y+6, color)
+	} else {
+		fmt.Fprintf(w, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`+"\n", x, y, color)
+	}
+	fmt.Fprintf(w, `<text x="%d" y="%d">%s</text>`+"\n", x+18, y+10, svgEscape(label))
+}
+
+// renderBurndown draws the number of cards not yet done at the end of each day, along with the
+// ideal line from the first day's remaining work down to zero.
+func renderBurndown(w io.Writer, name string, h *ListHistory, done Ref) error {
+	remaining := make([]int, len(h.Days))
+	max := 1
+	for day := range h.Days {
+		for _, list := range h.Lists {
+			if !done.Matches(list.ID, "", list.Name) {
+				remaining[day] += h.Count(day, list.ID)
+			}
+		}
+		if remaining[day] > max {
+			max = remaining[day]
+		}
+	}
+
+	f := chartFrame{days: len(h.Days), max: max}
+	f.begin(w, name+": Burndown", h)
+
+	last := len(h.Days) - 1
+	fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#97a0af" stroke-width="2" stroke-dasharray="4 3"/>`+"\n",
+		f.x(0), f.y(float64(remaining[0])), f.x(last), f.y(0))
+
+	points := make([]string, 0, len(remaining))
+	for day, value := range remaining {
+		points = append(points, fmt.Sprintf("%.1f,%.1f", f.x(day), f.y(float64(value))))
+	}
+	fmt.Fprintf(w, `<polyline points="%s" fill="none" stroke="#0079bf" stroke-width="2.5"/>`+"\n", strings.Join(points, " "))
+	for day, value := range remaining {
+		fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="3" fill="#0079bf"/>`+"\n", f.x(day), f.y(float64(value)))
+	}
+
+	f.legend(w, 0, "#0079bf", "Remaining cards", false)
+	f.legend(w, 1, "#97a0af", "Ideal", true)
+
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// renderCumulativeFlow draws a stacked area for each list, with the done list at the bottom and
+// the rest stacked in reverse board order, so that work flows downward over time.
+func renderCumulativeFlow(w io.Writer, name string, h *ListHistory, done Ref) error {
+	var layers []List
+	for _, list := range h.Lists {
+		if done.Matches(list.ID, "", list.Name) {
+			layers = append(layers, list)
+		}
+	}
+	for i := len(h.Lists) - 1; i >= 0; i-- {
+		if !done.Matches(h.Lists[i].ID, "", h.Lists[i].Name) {
+			layers = append(layers, h.Lists[i])
+		}
+	}
+
+	max := 1
+	for day := range h.Days {
+		total := 0
+		for _, list := range layers {
+			total += h.Count(day, list.ID)
+		}
+		if total > max {
+			max = total
+		}
+	}
+
+	f := chartFrame{days: len(h.Days), max: max}
+	f.begin(w, name+": Cumulative Flow", h)
+
+	lower := make([]int, len(h.Days))
+	for i, list := range layers {
+		upper := make([]int, len(h.Days))
+		for day := range h.Days {
+			upper[day] = lower[day] + h.Count(day, list.ID)
+		}
+
+		points := make([]string, 0, 2*len(h.Days))
+		for day := range h.Days {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", f.x(day), f.y(float64(upper[day]))))
+		}
+		for day := len(h.Days) - 1; day >= 0; day-- {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", f.x(day), f.y(float64(lower[day]))))
+		}
+
+		color := chartColors[i%len(chartColors)]
+		fmt.Fprintf(w, `<polygon points="%s" fill="%s" fill-opacity="0.85" stroke="%s"/>`+"\n", strings.Join(points, " "), color, color)
+
+		lower = upper
+	}
+
+	// List the legend top to bottom, matching the order of the layers on the chart.
+	for i := range layers {
+		j := len(layers) - 1 - i
+		f.legend(w, i, chartColors[j%len(chartColors)], layers[j].Name, false)
+	}
+
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+func svgEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// systemProfilePath holds settings shared by everyone on a machine. It is optional.
+const systemProfilePath = "/etc/sprint-closer.json"
+
+// envPrefix starts the name of each environment variable that sets a profile field, like
+// SPRINT_CLOSER_SPRINT_BOARD or SPRINT_CLOSER_REPORT_DIRECTORY.
+const envPrefix = "SPRINT_CLOSER"
+
+// Names of the configuration layers, from lowest to highest priority.
+const (
+	systemLayer  = "system file"
+	profileLayer = "profile file"
+	envLayer     = "environment"
+	flagLayer    = "command line"
+)
+
+// secretSettings are masked when the configuration is shown.
+var secretSettings = []string{"key", "token", "notify.email.password", "notify.webhook.url"}
+
+// configLayer is one source of profile settings.
+type configLayer struct {
+	Name     string
+	Settings map[string]interface{}
+}
+
+// loadLayers reads every configuration source that is present, lowest priority first.
+func loadLayers(path, name string, overrides map[string]interface{}) ([]configLayer, error) {
+	var layers []configLayer
+
+	system, err := readSettings(systemProfilePath)
+	if err != nil {
+		return nil, err
+	}
+	if system != nil {
+		delete(system, "profiles")
+		delete(system, "default_profile")
+		layers = append(layers, configLayer{Name: systemLayer, Settings: system})
+	}
+
+	profile, err := readSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		profile, err = selectProfile(profile, name)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, configLayer{Name: profileLayer, Settings: profile})
+	}
+
+	env, err := envSettings(reflect.TypeOf(Profile{}), envPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for variable, key := range map[string]string{"TRELLO_KEY": "key", "TRELLO_TOKEN": "token"} {
+		if value := os.Getenv(variable); value != "" {
+			if _, ok := env[key]; !ok {
+				env[key] = value
+			}
+		}
+	}
+	if len(env) > 0 {
+		layers = append(layers, configLayer{Name: envLayer, Settings: env})
+	}
+
+	if len(overrides) > 0 {
+		layers = append(layers, configLayer{Name: flagLayer, Settings: overrides})
+	}
+
+	return layers, nil
+}
+
+// readSettings reads a JSON settings file. It returns nil if there is no such file.
+func readSettings(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]interface{})
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return nil, fmt.Errorf("Unable to read the settings in [%s]: %v", path, err)
+	}
+	return settings, nil
+}
+
+func hasLayer(layers []configLayer, name string) bool {
+	for _, layer := range layers {
+		if layer.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// envSettings reads the environment variables that correspond to the JSON fields of a struct. Lists
+// of strings are separated by commas. Nested structs use the names of each enclosing field, like
+// SPRINT_CLOSER_NOTIFY_EMAIL_HOST.
+func envSettings(t reflect.Type, prefix string) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		variable := prefix + "_" + strings.ToUpper(name)
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct {
+			nested, err := envSettings(ft, variable)
+			if err != nil {
+				return nil, err
+			}
+			if len(nested) > 0 {
+				settings[name] = nested
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			continue
+		}
+
+		switch ft.Kind() {
+		case reflect.String:
+			settings[name] = value
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a whole number, not [%s].", variable, value)
+			}
+			settings[name] = n
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be true or false, not [%s].", variable, value)
+			}
+			settings[name] = b
+		case reflect.Slice:
+			if ft.Elem().Kind() != reflect.String {
+				continue
+			}
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			settings[name] = items
+		}
+	}
+
+	return settings, nil
+}
+
+// flagSettings collects the profile settings given as global flags.
+func flagSettings(c *cli.Context) map[string]interface{} {
+	settings := make(map[string]interface{})
+	if org := c.GlobalString("organization"); org != "" {
+		settings["organization"] = org
+	}
+	if board := c.GlobalString("sprint-board"); board != "" {
+		settings["sprint_board"] = board
+	}
+	return settings
+}
+
+// flattenSettings turns nested settings into a map keyed by dotted paths, like "report.directory".
+func flattenSettings(prefix string, settings map[string]interface{}, into map[string]interface{}) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenSettings(key, nested, into)
+		} else {
+			into[key] = value
+		}
+	}
+}
+
+// displaySetting formats one setting's value, masking secrets.
+func displaySetting(key string, value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		s = v
+	default:
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+
+	if containsString(secretSettings, key) && s != "" {
+		if len(s) <= 4 {
+			return "****"
+		}
+		return "********" + s[len(s)-4:]
+	}
+	return s
+}
+
+func configShow(c *cli.Context) {
+	setLogLevel(c)
+
+	path := c.GlobalString("profile")
+	name := c.GlobalString("profile-name")
+	overrides := flagSettings(c)
+
+	p, err := LoadProfile(path, name, overrides)
+	handleErr(err)
+
+	layers, err := loadLayers(path, name, overrides)
+	handleErr(err)
+
+	sources := make(map[string]string)
+	for _, layer := range layers {
+		flat := make(map[string]interface{})
+		flattenSettings("", layer.Settings, flat)
+
+		source := layer.Name
+		switch layer.Name {
+		case systemLayer:
+			source = systemProfilePath
+		case profileLayer:
+			source = path
+			if name != "" {
+				source += " (" + name + ")"
+			}
+		}
+
+		for key := range flat {
+			sources[key] = source
+		}
+	}
+
+	b, err := json.Marshal(p)
+	handleErr(err)
+
+	var effective map[string]interface{}
+	err = json.Unmarshal(b, &effective)
+	handleErr(err)
+
+	flat := make(map[string]interface{})
+	flattenSettings("", effective, flat)
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, key := range keys {
+		source, ok := sources[key]
+		if !ok && key == "token" && p.tokenSource != "" {
+			source, ok = p.tokenSource, true
+		}
+		if !ok {
+			source = "default"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, displaySetting(key, flat[key]), source)
+	}
+	w.Flush()
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
 )
@@ -21,8 +22,27 @@ type Connection struct {
 
 // Org contains a little information about a Trello organization.
 type Org struct {
-	ID        string
-	MemberIDs []string
+	ID      string
+	Members []Member
+}
+
+// Member looks up one of the organization's members by ID.
+func (o *Org) Member(id string) (Member, bool) {
+	for _, member := range o.Members {
+		if member.ID == id {
+			return member, true
+		}
+	}
+	return Member{}, false
+}
+
+// Board captures information about a Trello Board.
+type Board struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ShortLink string `json:"shortLink"`
+	URL       string `json:"url"`
+	Closed    bool   `json:"closed"`
 }
 
 // List captures information about a Trello List.
@@ -30,6 +50,135 @@ type List struct {
 	ID       string  `json:"id"`
 	Name     string  `json:"name"`
 	Position float64 `json:"pos"`
+	Closed   bool    `json:"closed"`
+}
+
+// Card captures information about a Trello Card.
+type Card struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc"`
+	ShortURL  string   `json:"shortUrl"`
+	ListID    string   `json:"idList"`
+	LabelIDs  []string `json:"idLabels"`
+	MemberIDs []string `json:"idMembers"`
+	Labels    []Label  `json:"labels"`
+	Closed    bool     `json:"closed"`
+
+	Members          []Member          `json:"members"`
+	CustomFieldItems []CustomFieldItem `json:"customFieldItems"`
+}
+
+// Created derives the time that a card was created from the timestamp embedded in its ID.
+func (c Card) Created() time.Time {
+	if len(c.ID) < 8 {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(c.ID[:8], 16, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
+
+// NumberField returns the value of a numeric custom field on a card that was fetched along with
+// its custom field items. Cards without a value report zero.
+func (c Card) NumberField(fieldID string) float64 {
+	for _, item := range c.CustomFieldItems {
+		if item.FieldID == fieldID && item.Value.Number != "" {
+			n, err := strconv.ParseFloat(item.Value.Number, 64)
+			if err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// Attachment captures information about a file or link attached to a Trello Card.
+type Attachment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Member captures information about a Trello Member.
+type Member struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+// Label captures information about a Trello Label.
+type Label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Checklist captures information about a checklist on a Trello Card.
+type Checklist struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	CardID     string      `json:"idCard"`
+	Position   float64     `json:"pos"`
+	CheckItems []CheckItem `json:"checkItems"`
+}
+
+// CheckItem is one entry on a Checklist.
+type CheckItem struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	State    string  `json:"state"`
+	Position float64 `json:"pos"`
+}
+
+// CustomField captures the definition of a custom field on a Trello board.
+type CustomField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// CustomFieldItem is the value of a custom field on a single card.
+type CustomFieldItem struct {
+	FieldID string `json:"idCustomField"`
+	Value   struct {
+		Number string `json:"number"`
+		Text   string `json:"text"`
+	} `json:"value"`
+}
+
+// Action is an entry in the history of a Trello board or card.
+type Action struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	Date            time.Time `json:"date"`
+	MemberCreatorID string    `json:"idMemberCreator"`
+	MemberCreator   *Member   `json:"memberCreator"`
+	Data            struct {
+		Card       ActionCard `json:"card"`
+		List       *ActionRef `json:"list"`
+		ListBefore *ActionRef `json:"listBefore"`
+		ListAfter  *ActionRef `json:"listAfter"`
+		Board      *ActionRef `json:"board"`
+	} `json:"data"`
+}
+
+// ActionCard describes the card that an Action refers to.
+type ActionCard struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	ListID string `json:"idList"`
+	Closed bool   `json:"closed"`
+}
+
+// ActionRef names an object that an Action refers to.
+type ActionRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 func (c Connection) url(parts []string, query map[string]string) string {
@@ -135,7 +284,7 @@ func (c Connection) delete(url string) error {
 }
 
 // CreateBoard creates a new Trello board.
-func (c Connection) CreateBoard(name string) (string, error) {
+func (c Connection) CreateBoard(name string) (*Board, error) {
 	u := c.url([]string{"boards"}, nil)
 
 	reqBody := map[string]string{
@@ -144,12 +293,31 @@ func (c Connection) CreateBoard(name string) (string, error) {
 		"prefs_permissonLevel": "org",
 	}
 
-	var resp struct {
-		ID string `json:"id"`
-	}
+	var resp Board
 
 	err := c.post(u, reqBody, &resp)
-	return resp.ID, err
+	return &resp, err
+}
+
+// SetBoardDescription replaces the description of a board.
+func (c Connection) SetBoardDescription(boardID, desc string) error {
+	u := c.url([]string{"boards", boardID}, nil)
+
+	return c.put(u, map[string]string{"desc": desc}, nil)
+}
+
+// FindMe returns the member associated with the token we're using.
+func (c Connection) FindMe() (*Member, error) {
+	u := c.url([]string{"members", "me"}, map[string]string{
+		"fields": "username,fullName",
+	})
+
+	var me Member
+	err := c.get(u, &me)
+	if err != nil {
+		return nil, err
+	}
+	return &me, nil
 }
 
 // FindMyUserID returns the user ID associated with the token we're using.
@@ -164,18 +332,71 @@ func (c Connection) FindMyUserID() (string, error) {
 	return respBody.ID, err
 }
 
+// TokenPermission describes what a token may do to one kind of Trello object.
+type TokenPermission struct {
+	ModelID   string `json:"idModel"`
+	ModelType string `json:"modelType"`
+	Read      bool   `json:"read"`
+	Write     bool   `json:"write"`
+}
+
+// GetTokenPermissions returns the permissions granted to the token we're using.
+func (c Connection) GetTokenPermissions() ([]TokenPermission, error) {
+	u := c.url([]string{"tokens", c.profile.Token}, map[string]string{
+		"fields": "permissions",
+	})
+
+	var respBody struct {
+		Permissions []TokenPermission `json:"permissions"`
+	}
+
+	err := c.get(u, &respBody)
+	return respBody.Permissions, err
+}
+
+// Membership records the role that a member has on a board.
+type Membership struct {
+	MemberID   string `json:"idMember"`
+	MemberType string `json:"memberType"`
+}
+
+// GetBoardMemberships returns the role of each member of a board.
+func (c Connection) GetBoardMemberships(boardID string) ([]Membership, error) {
+	u := c.url([]string{"boards", boardID, "memberships"}, nil)
+
+	var memberships []Membership
+	err := c.get(u, &memberships)
+	return memberships, err
+}
+
+// OrgSummary names a Trello organization.
+type OrgSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// ListMyOrgs returns the organizations that the token's user belongs to.
+func (c Connection) ListMyOrgs() ([]OrgSummary, error) {
+	u := c.url([]string{"members", "me", "organizations"}, map[string]string{
+		"fields": "name,displayName",
+	})
+
+	var orgs []OrgSummary
+	err := c.get(u, &orgs)
+	return orgs, err
+}
+
 // FindOrg looks up the ID and members of the configured organization.
 func (c Connection) FindOrg() (*Org, error) {
 	u := c.url([]string{"organizations", c.profile.Organization}, map[string]string{
-		"members": "all",
+		"members":       "all",
+		"member_fields": "username,fullName",
 	})
 
 	var respBody struct {
-		ID      string `json:"id"`
-		Members []struct {
-			ID       string `json:"id"`
-			Username string `json:"username"`
-		} `json:"members"`
+		ID      string   `json:"id"`
+		Members []Member `json:"members"`
 	}
 
 	err := c.get(u, &respBody)
@@ -183,14 +404,9 @@ func (c Connection) FindOrg() (*Org, error) {
 		return nil, err
 	}
 
-	memberIDs := make([]string, 0, len(respBody.Members))
-	for _, member := range respBody.Members {
-		memberIDs = append(memberIDs, member.ID)
-	}
-
 	return &Org{
-		ID:        respBody.ID,
-		MemberIDs: memberIDs,
+		ID:      respBody.ID,
+		Members: respBody.Members,
 	}, err
 }
 
@@ -203,34 +419,71 @@ func (c Connection) AddMember(boardID string, memberID string) error {
 	return c.put(u, nil, nil)
 }
 
-// FindBoard discovers the ID of an existing board by name.
-func (c Connection) FindBoard(name string) (string, error) {
+// FindBoard discovers the ID of an existing, open board by ID, shortLink, URL, name, or pattern.
+func (c Connection) FindBoard(ref string) (string, error) {
+	board, err := c.LookupBoard(ref)
+	if err != nil {
+		return "", err
+	}
+	return board.ID, nil
+}
+
+// ListBoards returns every board in the organization, including closed ones.
+func (c Connection) ListBoards() ([]Board, error) {
 	u := c.url([]string{"organizations", c.profile.Organization, "boards"}, map[string]string{
-		"fields": "name",
+		"filter": "all",
+		"fields": "name,shortLink,url,closed",
 	})
 
-	var boardResults []struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
+	var boards []Board
+	err := c.get(u, &boards)
+	return boards, err
+}
+
+// LookupBoard locates an existing, open board by ID, shortLink, URL, name, or pattern.
+func (c Connection) LookupBoard(ref string) (*Board, error) {
+	r, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
 	}
 
-	err := c.get(u, &boardResults)
+	u := c.url([]string{"organizations", c.profile.Organization, "boards"}, map[string]string{
+		"filter": "open",
+		"fields": "name,shortLink,url,closed",
+	})
+
+	var boardResults []Board
+
+	err = c.get(u, &boardResults)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	var matches []Board
 	for _, board := range boardResults {
 		log.WithFields(log.Fields{
-			"name": board.Name,
-			"id":   board.ID,
+			"name":   board.Name,
+			"id":     board.ID,
+			"closed": board.Closed,
 		}).Debug("Board")
 
-		if board.Name == name {
-			return board.ID, nil
+		if !board.Closed && r.Matches(board.ID, board.ShortLink, board.Name) {
+			matches = append(matches, board)
 		}
 	}
 
-	return "", fmt.Errorf("Unable to find a board with the name [%s].", name)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("Unable to find an open board matching [%s].", ref)
+	case 1:
+		return &matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, board := range matches {
+			names = append(names, fmt.Sprintf("%s (%s)", board.Name, board.ID))
+		}
+		return nil, ambiguousRefError("board", r, names)
+	}
 }
 
 // GetListIDs returns an array of IDs of the lists on an existing board.
@@ -263,30 +516,50 @@ func (c Connection) DeleteList(listID string) error {
 	return c.put(u, nil, nil)
 }
 
-// FindList locates a list on a board by name.
-func (c Connection) FindList(name string, boardID string) (*List, error) {
-	u := c.url([]string{"boards", boardID, "lists"}, nil)
+// FindList locates an open list on a board by ID, name, or pattern.
+func (c Connection) FindList(ref string, boardID string) (*List, error) {
+	r, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.url([]string{"boards", boardID, "lists"}, map[string]string{
+		"filter": "open",
+	})
 
 	var listResults []List
 
-	err := c.get(u, &listResults)
+	err = c.get(u, &listResults)
 	if err != nil {
 		return nil, err
 	}
 
+	var matches []List
 	for _, list := range listResults {
 		log.WithFields(log.Fields{
 			"name":     list.Name,
 			"id":       list.ID,
 			"position": list.Position,
+			"closed":   list.Closed,
 		}).Debug("List")
 
-		if list.Name == name {
-			return &list, nil
+		if !list.Closed && r.Matches(list.ID, "", list.Name) {
+			matches = append(matches, list)
 		}
 	}
 
-	return nil, fmt.Errorf("Unable to find a list with the name [%s].", name)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("Unable to find an open list matching [%s].", ref)
+	case 1:
+		return &matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, list := range matches {
+			names = append(names, fmt.Sprintf("%s (%s)", list.Name, list.ID))
+		}
+		return nil, ambiguousRefError("list", r, names)
+	}
 }
 
 // MoveList moves a list to a different board.
@@ -308,12 +581,400 @@ func (c Connection) MoveList(listID string, toBoardID string, position int) erro
 	return c.put(u, &params, nil)
 }
 
-// AddList creates a new list on the specified board at the given position.
-func (c Connection) AddList(name, boardID string, position float64) error {
+// AddList creates a new list on the specified board at the given position and returns its ID.
+func (c Connection) AddList(name, boardID string, position float64) (string, error) {
 	u := c.url([]string{"boards", boardID, "lists"}, map[string]string{
 		"name": name,
 		"pos":  strconv.FormatFloat(position, 'f', 1, 64),
 	})
 
+	var resp struct {
+		ID string `json:"id"`
+	}
+
+	err := c.post(u, nil, &resp)
+	return resp.ID, err
+}
+
+// GetCards returns the open cards on a list along with their members and custom field values.
+func (c Connection) GetCards(listID string) ([]Card, error) {
+	u := c.url([]string{"lists", listID, "cards"}, map[string]string{
+		"filter":           "open",
+		"members":          "true",
+		"member_fields":    "username,fullName",
+		"customFieldItems": "true",
+	})
+
+	var cards []Card
+	err := c.get(u, &cards)
+	return cards, err
+}
+
+// GetBoardCards returns the open cards on a board along with their members and custom field values.
+func (c Connection) GetBoardCards(boardID string) ([]Card, error) {
+	u := c.url([]string{"boards", boardID, "cards"}, map[string]string{
+		"filter":           "open",
+		"members":          "true",
+		"member_fields":    "username,fullName",
+		"customFieldItems": "true",
+	})
+
+	var cards []Card
+	err := c.get(u, &cards)
+	return cards, err
+}
+
+// GetLists returns every list on a board, including closed ones, ordered by position.
+func (c Connection) GetLists(boardID string) ([]List, error) {
+	u := c.url([]string{"boards", boardID, "lists"}, map[string]string{
+		"filter": "all",
+	})
+
+	var lists []List
+	err := c.get(u, &lists)
+	return lists, err
+}
+
+// GetBoardActions returns the history of a board since a moment, newest first, limited to the given
+// action types. It follows Trello's paging until every matching action has been read.
+func (c Connection) GetBoardActions(boardID string, since time.Time, types ...string) ([]Action, error) {
+	var all []Action
+	before := ""
+
+	for {
+		query := map[string]string{
+			"filter": strings.Join(types, ","),
+			"since":  since.UTC().Format(time.RFC3339),
+			"limit":  "1000",
+		}
+		if before != "" {
+			query["before"] = before
+		}
+
+		var page []Action
+		err := c.get(c.url([]string{"boards", boardID, "actions"}, query), &page)
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, page...)
+		if len(page) < 1000 {
+			return all, nil
+		}
+		before = page[len(page)-1].ID
+	}
+}
+
+// ExportBoard returns a board exactly as Trello describes it, along with its lists, cards,
+// checklists, labels, members and custom fields.
+func (c Connection) ExportBoard(boardID string) (json.RawMessage, error) {
+	u := c.url([]string{"boards", boardID}, map[string]string{
+		"lists":                 "all",
+		"cards":                 "all",
+		"card_customFieldItems": "true",
+		"checklists":            "all",
+		"labels":                "all",
+		"labels_limit":          "1000",
+		"members":               "all",
+		"customFields":          "true",
+	})
+
+	var board json.RawMessage
+	err := c.get(u, &board)
+	return board, err
+}
+
+// ExportBoardActions returns the complete history of a board, newest first, exactly as Trello
+// describes it. It follows Trello's paging until every action has been read.
+func (c Connection) ExportBoardActions(boardID string) ([]json.RawMessage, error) {
+	var all []json.RawMessage
+	before := ""
+
+	for {
+		query := map[string]string{
+			"filter": "all",
+			"limit":  "1000",
+		}
+		if before != "" {
+			query["before"] = before
+		}
+
+		var page []json.RawMessage
+		err := c.get(c.url([]string{"boards", boardID, "actions"}, query), &page)
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, page...)
+		if len(page) < 1000 {
+			return all, nil
+		}
+
+		var last struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(page[len(page)-1], &last); err != nil {
+			return all, err
+		}
+		before = last.ID
+	}
+}
+
+// FindBoardCreator returns the member who created a board, or nil if Trello no longer knows.
+func (c Connection) FindBoardCreator(boardID string) (*Member, error) {
+	u := c.url([]string{"boards", boardID, "actions"}, map[string]string{
+		"filter":               "createBoard",
+		"memberCreator_fields": "username,fullName",
+	})
+
+	var actions []Action
+	err := c.get(u, &actions)
+	if err != nil || len(actions) == 0 {
+		return nil, err
+	}
+
+	return actions[0].MemberCreator, nil
+}
+
+// GetAttachments returns the attachments on a card.
+func (c Connection) GetAttachments(cardID string) ([]Attachment, error) {
+	u := c.url([]string{"cards", cardID, "attachments"}, map[string]string{
+		"fields": "name,url",
+	})
+
+	var attachments []Attachment
+	err := c.get(u, &attachments)
+	return attachments, err
+}
+
+// GetCardActions returns the history of a card, newest first, limited to the given action types.
+func (c Connection) GetCardActions(cardID string, types ...string) ([]Action, error) {
+	u := c.url([]string{"cards", cardID, "actions"}, map[string]string{
+		"filter": strings.Join(types, ","),
+		"limit":  "1000",
+	})
+
+	var actions []Action
+	err := c.get(u, &actions)
+	return actions, err
+}
+
+// AddCard creates a new card at the bottom of a list and returns its ID.
+func (c Connection) AddCard(listID, name, desc string) (string, error) {
+	u := c.url([]string{"cards"}, nil)
+
+	reqBody := map[string]string{
+		"idList": listID,
+		"name":   name,
+		"desc":   desc,
+		"pos":    "bottom",
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+
+	err := c.post(u, reqBody, &resp)
+	return resp.ID, err
+}
+
+// RestoreCard creates a card at a position in a list, with its due date, labels and members, and
+// returns its ID.
+func (c Connection) RestoreCard(listID string, card Card, position float64, due string) (string, error) {
+	u := c.url([]string{"cards"}, nil)
+
+	reqBody := map[string]string{
+		"idList":    listID,
+		"name":      card.Name,
+		"desc":      card.Desc,
+		"pos":       strconv.FormatFloat(position, 'f', -1, 64),
+		"idLabels":  strings.Join(card.LabelIDs, ","),
+		"idMembers": strings.Join(card.MemberIDs, ","),
+	}
+	if due != "" {
+		reqBody["due"] = due
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+
+	err := c.post(u, reqBody, &resp)
+	return resp.ID, err
+}
+
+// AddChecklist creates a checklist on a card and returns its ID.
+func (c Connection) AddChecklist(cardID, name string, position float64) (string, error) {
+	u := c.url([]string{"checklists"}, map[string]string{
+		"idCard": cardID,
+		"name":   name,
+		"pos":    strconv.FormatFloat(position, 'f', -1, 64),
+	})
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+
+	err := c.post(u, nil, &resp)
+	return resp.ID, err
+}
+
+// AddCheckItem adds an item to the bottom of a checklist and returns its ID.
+func (c Connection) AddCheckItem(checklistID, name string, checked bool) (string, error) {
+	u := c.url([]string{"checklists", checklistID, "checkItems"}, map[string]string{
+		"name":    name,
+		"pos":     "bottom",
+		"checked": strconv.FormatBool(checked),
+	})
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+
+	err := c.post(u, nil, &resp)
+	return resp.ID, err
+}
+
+// MoveCard moves a card to the bottom of a different list on the same board.
+func (c Connection) MoveCard(cardID, listID string) error {
+	u := c.url([]string{"cards", cardID}, map[string]string{
+		"idList": listID,
+		"pos":    "bottom",
+	})
+
+	return c.put(u, nil, nil)
+}
+
+// GetLabels returns every label defined on a board.
+func (c Connection) GetLabels(boardID string) ([]Label, error) {
+	u := c.url([]string{"boards", boardID, "labels"}, map[string]string{
+		"fields": "name,color",
+		"limit":  "1000",
+	})
+
+	var labels []Label
+	err := c.get(u, &labels)
+	return labels, err
+}
+
+// CreateLabel defines a new label on a board and returns its ID.
+func (c Connection) CreateLabel(boardID, name, color string) (string, error) {
+	u := c.url([]string{"labels"}, map[string]string{
+		"idBoard": boardID,
+		"name":    name,
+		"color":   color,
+	})
+
+	var created Label
+	err := c.post(u, nil, &created)
+	return created.ID, err
+}
+
+// EnsureLabel returns the ID of the label with the given name on a board, creating it if necessary.
+func (c Connection) EnsureLabel(boardID, name, color string) (string, error) {
+	labels, err := c.GetLabels(boardID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, label := range labels {
+		if label.Name == name {
+			return label.ID, nil
+		}
+	}
+
+	return c.CreateLabel(boardID, name, color)
+}
+
+// AddLabel applies an existing label to a card.
+func (c Connection) AddLabel(cardID, labelID string) error {
+	u := c.url([]string{"cards", cardID, "idLabels"}, map[string]string{
+		"value": labelID,
+	})
+
 	return c.post(u, nil, nil)
 }
+
+// FindNumberField returns the ID of the numeric custom field with the given name on a board, or ""
+// if the board has no such field.
+func (c Connection) FindNumberField(boardID, name string) (string, error) {
+	u := c.url([]string{"boards", boardID, "customFields"}, nil)
+
+	var fields []CustomField
+	err := c.get(u, &fields)
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range fields {
+		if field.Name == name {
+			if field.Type != "number" {
+				return "", fmt.Errorf("The custom field [%s] has type [%s], not number.", name, field.Type)
+			}
+			return field.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// EnsureNumberField returns the ID of the numeric custom field with the given name on a board,
+// creating it if necessary. The board must have the Custom Fields power-up enabled.
+func (c Connection) EnsureNumberField(boardID, name string) (string, error) {
+	fieldID, err := c.FindNumberField(boardID, name)
+	if err != nil || fieldID != "" {
+		return fieldID, err
+	}
+
+	u := c.url([]string{"customFields"}, nil)
+
+	reqBody := map[string]interface{}{
+		"idModel":           boardID,
+		"modelType":         "board",
+		"name":              name,
+		"type":              "number",
+		"pos":               "bottom",
+		"display_cardFront": true,
+	}
+
+	var created CustomField
+	err = c.post(u, reqBody, &created)
+	return created.ID, err
+}
+
+// customFieldsPluginID identifies Trello's Custom Fields power-up.
+const customFieldsPluginID = "56d5e249a98895a9797bebb9"
+
+// HasCustomFields returns true if the Custom Fields power-up is enabled on a board.
+func (c Connection) HasCustomFields(boardID string) (bool, error) {
+	u := c.url([]string{"boards", boardID, "boardPlugins"}, nil)
+
+	var plugins []struct {
+		PluginID string `json:"idPlugin"`
+	}
+
+	err := c.get(u, &plugins)
+	if err != nil {
+		return false, err
+	}
+
+	for _, plugin := range plugins {
+		if plugin.PluginID == customFieldsPluginID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetNumberField sets the value of a numeric custom field on a card.
+func (c Connection) SetNumberField(cardID, fieldID string, value float64) error {
+	u := c.url([]string{"card", cardID, "customField", fieldID, "item"}, nil)
+
+	reqBody := map[string]interface{}{
+		"value": map[string]string{
+			"number": strconv.FormatFloat(value, 'f', -1, 64),
+		},
+	}
+
+	return c.put(u, reqBody, nil)
+}
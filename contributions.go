@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// Contributor counts the completed cards and points assigned to one member.
+type Contributor struct {
+	Member
+	Cards  int
+	Points float64
+}
+
+// DisplayName names a contributor as well as we can: by full name and username, by username
+// alone, or by ID if the member is no longer known.
+func (c Contributor) DisplayName() string {
+	switch {
+	case c.FullName != "" && c.Username != "":
+		return fmt.Sprintf("%s (@%s)", c.FullName, c.Username)
+	case c.Username != "":
+		return "@" + c.Username
+	default:
+		return c.ID
+	}
+}
+
+type byContribution []Contributor
+
+func (s byContribution) Len() int      { return len(s) }
+func (s byContribution) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byContribution) Less(i, j int) bool {
+	if s[i].Cards != s[j].Cards {
+		return s[i].Cards > s[j].Cards
+	}
+	if s[i].Points != s[j].Points {
+		return s[i].Points > s[j].Points
+	}
+	return s[i].DisplayName() < s[j].DisplayName()
+}
+
+// tallyContributions counts the cards and points assigned to each member, most prolific first.
+// Members are named from the org when it is given, and from the cards themselves otherwise.
+func tallyContributions(cards []Card, points func(Card) float64, org *Org) []Contributor {
+	byMember := make(map[string]*Contributor)
+	var order []string
+
+	for _, card := range cards {
+		memberIDs := card.MemberIDs
+		if len(memberIDs) == 0 {
+			for _, member := range card.Members {
+				memberIDs = append(memberIDs, member.ID)
+			}
+		}
+
+		for _, id := range memberIDs {
+			c, ok := byMember[id]
+			if !ok {
+				c = &Contributor{Member: resolveMember(id, card, org)}
+				byMember[id] = c
+				order = append(order, id)
+			}
+			c.Cards++
+			c.Points += points(card)
+		}
+	}
+
+	contributors := make([]Contributor, 0, len(order))
+	for _, id := range order {
+		contributors = append(contributors, *byMember[id])
+	}
+	sort.Sort(byContribution(contributors))
+	return contributors
+}
+
+// resolveMember finds the username and full name for a member ID.
+func resolveMember(id string, card Card, org *Org) Member {
+	if org != nil {
+		if member, ok := org.Member(id); ok {
+			return member
+		}
+	}
+
+	for _, member := range card.Members {
+		if member.ID == id {
+			return member
+		}
+	}
+
+	return Member{ID: id}
+}
+
+func contributions(c *cli.Context) {
+	p, conn := setup(c)
+
+	org, err := conn.FindOrg()
+	handleErr(err)
+
+	var boards []Board
+	if count := c.Int("sprints"); count > 0 {
+		sprints, err := findArchivedSprints(conn)
+		handleErr(err)
+
+		if len(sprints) > count {
+			sprints = sprints[len(sprints)-count:]
+		}
+		for _, sprint := range sprints {
+			boards = append(boards, sprint.Board)
+		}
+	} else {
+		board, err := findArchiveBoard(conn, c.Args().First())
+		handleErr(err)
+		boards = append(boards, *board)
+	}
+
+	var cards []Card
+	points := make(map[string]float64)
+	for _, board := range boards {
+		doneList, err := conn.FindList(p.DoneList, board.ID)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"board name": board.Name,
+				"error":      err,
+			}).Warn("Skipping sprint.")
+			continue
+		}
+
+		boardCards, err := conn.GetCards(doneList.ID)
+		handleErr(err)
+
+		reader, err := newPointsReader(conn, p.Points, board.ID)
+		handleErr(err)
+
+		for _, card := range boardCards {
+			points[card.ID] = reader.Points(card)
+		}
+		cards = append(cards, boardCards...)
+	}
+
+	// Custom field IDs differ between boards, so points were read board by board above.
+	contributors := tallyContributions(cards, func(card Card) float64 { return points[card.ID] }, org)
+
+	if len(boards) == 1 {
+		fmt.Printf("%s: %d cards.\n\n", boards[0].Name, len(cards))
+	} else {
+		fmt.Printf("%d sprints: %d cards.\n\n", len(boards), len(cards))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "MEMBER\tCARDS\tPOINTS")
+	for _, contributor := range contributors {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", contributor.DisplayName(), contributor.Cards, formatPoints(contributor.Points))
+	}
+	w.Flush()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// doctorCheck is one line of the checklist printed by the doctor command.
+type doctorCheck struct {
+	Name string
+	Err  error
+
+	// Hint suggests a fix when the check fails.
+	Hint string
+}
+
+// checklist collects the results of the doctor command's checks.
+type checklist []doctorCheck
+
+// check records the outcome of a check and prints any failure as soon as it happens.
+func (l *checklist) check(name string, err error, hint string) bool {
+	*l = append(*l, doctorCheck{Name: name, Err: err, Hint: hint})
+
+	if err != nil {
+		fmt.Printf("%s: %s\n", name, firstLine(err.Error()))
+		if hint != "" {
+			fmt.Printf("  %s\n", hint)
+		}
+	}
+	return err == nil
+}
+
+// firstLine drops the response body that follows an unexpected status code.
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func doctor(c *cli.Context) {
+	setLogLevel(c)
+
+	var results checklist
+	defer func() {
+		fmt.Println()
+
+		failed := 0
+		for _, each := range results {
+			status := "PASS"
+			if each.Err != nil {
+				status = "FAIL"
+				failed++
+			}
+			fmt.Printf("[%s] %s\n", status, each.Name)
+		}
+
+		if failed > 0 {
+			handleErr(fmt.Errorf("\n%d of %d checks failed.", failed, len(results)))
+		}
+	}()
+
+	path := c.GlobalString("profile")
+
+	var permErr error
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0004 != 0 {
+		permErr = fmt.Errorf("%s can be read by anyone (mode %s)", path, info.Mode().Perm())
+	}
+
+	p, err := LoadProfile(path, c.GlobalString("profile-name"), flagSettings(c))
+	if !results.check("Profile loads", err, "See the Installation and Configuration section of the README.") {
+		return
+	}
+	results.check("Profile is private", permErr, fmt.Sprintf("Run: chmod 600 %s", path))
+
+	conn := Connection{profile: *p}
+
+	me, err := conn.FindMe()
+	if !results.check("Key and token are accepted", err,
+		"Generate a new token at "+fmt.Sprintf(authorizeURL, p.Key)) {
+		return
+	}
+	fmt.Printf("Signed in as %s (%s).\n", me.Username, me.FullName)
+
+	org, err := conn.FindOrg()
+	if results.check("Organization resolves", err,
+		"Use the last part of the organization's URL, like \"automationtesting2\" for https://trello.com/automationtesting2.") {
+		fmt.Printf("Organization %s has %d members.\n", p.Organization, len(org.Members))
+
+		_, ok := org.Member(me.ID)
+		var memberErr error
+		if !ok {
+			memberErr = fmt.Errorf("%s is not a member of %s", me.Username, p.Organization)
+		}
+		results.check("You are an organization member", memberErr, "Ask an organization admin to invite you.")
+	}
+
+	board, err := conn.LookupBoard(p.SprintBoard)
+	if results.check("Sprint board found", err, "Set sprint_board to the name, shortLink or URL of the sprint board.") {
+		fmt.Printf("Sprint board: %s %s\n", board.Name, board.URL)
+
+		for _, ref := range p.ArchiveLists {
+			_, err := conn.FindList(ref, board.ID)
+			results.check(fmt.Sprintf("Archive list [%s] found", ref), err,
+				"Set archive_lists to lists that exist on the sprint board.")
+		}
+	}
+
+	sprints, err := findArchivedSprints(conn)
+	if results.check("Archive boards listed", err, "") {
+		fmt.Printf("%d archived sprints.\n", len(sprints))
+		for i := len(sprints) - 1; i >= 0 && i >= len(sprints)-5; i-- {
+			fmt.Printf("  %s %s\n", sprints[i].Name, sprints[i].URL)
+		}
+	}
+
+	now := time.Now()
+	zone, offset := now.Zone()
+	fmt.Printf("Local time is %s (%s, UTC%+03d:%02d).\n", now.Format("Mon 2006-01-02 15:04"), zone, offset/3600, abs(offset%3600)/60)
+	fmt.Printf("Sprints end on the most recent Friday in local time. A close now archives to [%s].\n", newBoardName())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
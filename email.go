@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// defaultEmailSubject is the subject line used when no subject template is configured.
+const defaultEmailSubject = `{{.Sprint}}: {{.CardsDone}} cards done`
+
+// EmailConfig describes the SMTP server and recipients that receive the sprint report.
+type EmailConfig struct {
+	Host string `json:"host"`
+
+	// Port defaults to 587.
+	Port int `json:"port"`
+
+	// StartTLS upgrades the connection before authenticating. Defaults to true.
+	StartTLS *bool `json:"starttls"`
+
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	From string   `json:"from"`
+	To   []string `json:"to"`
+
+	// Subject is a Go text/template that produces the subject line from a SprintSummary.
+	Subject string `json:"subject"`
+}
+
+// EmailNotifier mails the sprint report as plain text and HTML.
+type EmailNotifier struct {
+	config  EmailConfig
+	subject *template.Template
+	from    *mail.Address
+	to      []*mail.Address
+}
+
+// NewEmailNotifier validates an email configuration.
+func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
+	if config.From == "" {
+		return nil, errors.New("The email notifier needs a from address.")
+	}
+
+	if len(config.To) == 0 {
+		return nil, errors.New("The email notifier needs at least one recipient.")
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid email from address [%s]: %v", config.From, err)
+	}
+
+	to := make([]*mail.Address, 0, len(config.To))
+	for _, each := range config.To {
+		addr, err := mail.ParseAddress(each)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid email recipient [%s]: %v", each, err)
+		}
+		to = append(to, addr)
+	}
+
+	if config.Port == 0 {
+		config.Port = 587
+	}
+
+	source := config.Subject
+	if source == "" {
+		source = defaultEmailSubject
+	}
+
+	subject, err := template.New("subject").Funcs(template.FuncMap{
+		"points": formatPoints,
+	}).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid email subject template: %v", err)
+	}
+
+	return &EmailNotifier{config: config, subject: subject, from: from, to: to}, nil
+}
+
+// message renders the complete email, headers included, for a summary.
+func (n *EmailNotifier) message(s *SprintSummary) ([]byte, error) {
+	var subject bytes.Buffer
+	if err := n.subject.Execute(&subject, s); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		formatter   Formatter
+	}{
+		{"text/plain; charset=utf-8", MarkdownFormatter{}},
+		{"text/html; charset=utf-8", HTMLFormatter{}},
+	}
+
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if err := part.formatter.Format(qw, s.Report); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := []struct{ name, value string }{
+		{"From", n.config.From},
+		{"To", strings.Join(n.config.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject.String())},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h.name, h.value)
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// Notify implements Notifier.
+func (n *EmailNotifier) Notify(s *SprintSummary) error {
+	msg, err := n.message(s)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if n.config.StartTLS == nil || *n.config.StartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	}
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(n.from.Address); err != nil {
+		return err
+	}
+
+	for _, to := range n.to {
+		if err := c.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	log.WithField("recipients", strings.Join(n.config.To, ", ")).Info("Emailed the sprint report.")
+	return c.Quit()
+}
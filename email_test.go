@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// smtpSink is a fake SMTP server that accepts one message.
+type smtpSink struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpSink{listener: l, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *smtpSink) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.to = append(s.to, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data []string
+			for {
+				dl, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dl == ".\r\n" {
+					break
+				}
+				data = append(data, dl)
+			}
+			s.data = strings.Join(data, "")
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Unknown command")
+		}
+	}
+}
+
+func TestEmailNotify(t *testing.T) {
+	sink := newSMTPSink(t)
+	defer sink.listener.Close()
+
+	addr := sink.listener.Addr().(*net.TCPAddr)
+	starttls := false
+
+	n, err := NewEmailNotifier(EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		StartTLS: &starttls,
+		From:     "Sprint Closer <closer@example.com>",
+		To:       []string{"team@example.com", "Lead <lead@example.com>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := testSummary()
+	s.Report = &SprintReport{
+		Name:     s.Sprint,
+		BoardURL: s.BoardURL,
+		Cards:    []Card{{Name: "Ship the thing", ShortURL: "https://trello.com/c/AbC"}},
+	}
+
+	if err := n.Notify(s); err != nil {
+		t.Fatal(err)
+	}
+	<-sink.done
+
+	if !strings.HasPrefix(sink.from, "MAIL FROM:<closer@example.com>") {
+		t.Errorf("MAIL = %q", sink.from)
+	}
+	if len(sink.to) != 2 || sink.to[1] != "RCPT TO:<lead@example.com>" {
+		t.Errorf("RCPT = %q", sink.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(sink.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subject := msg.Header.Get("Subject"); subject != "DevEx Sprint 2026-10-16: 4 cards done" {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		b, err := ioutil.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[part.Header.Get("Content-Type")] = string(b)
+	}
+
+	text := parts["text/plain; charset=utf-8"]
+	if !strings.Contains(text, "Ship the thing") {
+		t.Errorf("text part missing the card: %q", text)
+	}
+
+	html := parts["text/html; charset=utf-8"]
+	if !strings.Contains(html, "<") || !strings.Contains(html, "Ship the thing") {
+		t.Errorf("HTML part missing the card: %q", html)
+	}
+}
+
+func TestEmailInvalidAddresses(t *testing.T) {
+	if _, err := NewEmailNotifier(EmailConfig{From: "not an address", To: []string{"a@example.com"}}); err == nil {
+		t.Error("expected an invalid from address to be rejected")
+	}
+	if _, err := NewEmailNotifier(EmailConfig{From: "a@example.com", To: []string{"@@"}}); err == nil {
+		t.Error("expected an invalid recipient to be rejected")
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// flowActionTypes are the card actions that record a card being created or changing lists.
+var flowActionTypes = []string{
+	"createCard",
+	"copyCard",
+	"convertToCardFromCheckItem",
+	"moveCardToBoard",
+	"updateCard:idList",
+}
+
+// FlowConfig controls how cycle and lead times are measured.
+type FlowConfig struct {
+	// InProgressLists identifies the lists that mark the start of work on a card. Defaults to
+	// "In Progress".
+	InProgressLists []string `json:"in_progress_lists"`
+}
+
+// CardFlow records when a completed card was created, when work on it started, and when it was
+// done.
+type CardFlow struct {
+	Card    Card
+	Created time.Time
+	Started time.Time
+	Done    time.Time
+}
+
+// LeadTime is the time from a card's creation until it was done.
+func (f CardFlow) LeadTime() time.Duration {
+	return f.Done.Sub(f.Created)
+}
+
+// CycleTime is the time from when work on a card started until it was done. It reports false if
+// the card never passed through an in-progress list.
+func (f CardFlow) CycleTime() (time.Duration, bool) {
+	if f.Started.IsZero() || f.Started.After(f.Done) {
+		return 0, false
+	}
+	return f.Done.Sub(f.Started), true
+}
+
+// Percentiles summarizes a set of durations.
+type Percentiles struct {
+	N   int
+	P50 time.Duration
+	P85 time.Duration
+	P95 time.Duration
+}
+
+// FlowSummary holds the lead and cycle time percentiles of a group of cards.
+type FlowSummary struct {
+	Label string
+	Lead  Percentiles
+	Cycle Percentiles
+}
+
+// inProgressRefs parses the lists that mark the start of work on a card.
+func (config FlowConfig) inProgressRefs() ([]Ref, error) {
+	refs := config.InProgressLists
+	if len(refs) == 0 {
+		refs = []string{"In Progress"}
+	}
+
+	inProgress := make([]Ref, 0, len(refs))
+	for _, each := range refs {
+		r, err := ParseRef(each)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read flow.in_progress_lists: %v", err)
+		}
+		inProgress = append(inProgress, r)
+	}
+	return inProgress, nil
+}
+
+// measureFlow reads the history of each card to find when it was created, started and done. Cards
+// without a recorded move into the done list are treated as done at the fallback time.
+func measureFlow(conn Connection, inProgress []Ref, doneRef string, cards []Card, fallback time.Time) ([]CardFlow, error) {
+	done, err := ParseRef(doneRef)
+	if err != nil {
+		return nil, err
+	}
+
+	flows := make([]CardFlow, 0, len(cards))
+	for _, card := range cards {
+		log.WithField("card id", card.ID).Debug("Reading card history")
+
+		actions, err := conn.GetCardActions(card.ID, flowActionTypes...)
+		if err != nil {
+			return nil, err
+		}
+
+		flow := CardFlow{Card: card, Created: card.Created()}
+
+		// Actions arrive newest first.
+		for i := len(actions) - 1; i >= 0; i-- {
+			action := actions[i]
+
+			switch action.Type {
+			case "createCard", "copyCard", "convertToCardFromCheckItem":
+				if action.Date.Before(flow.Created) || flow.Created.IsZero() {
+					flow.Created = action.Date
+				}
+			}
+
+			entered := action.Data.ListAfter
+			if action.Type != "updateCard" {
+				entered = action.Data.List
+			}
+			if entered == nil {
+				continue
+			}
+
+			if flow.Started.IsZero() && matchesAny(inProgress, entered) {
+				flow.Started = action.Date
+			}
+
+			if done.Matches(entered.ID, "", entered.Name) {
+				flow.Done = action.Date
+			}
+		}
+
+		if flow.Done.IsZero() {
+			flow.Done = fallback
+		}
+
+		flows = append(flows, flow)
+	}
+
+	return flows, nil
+}
+
+func matchesAny(refs []Ref, list *ActionRef) bool {
+	for _, r := range refs {
+		if r.Matches(list.ID, "", list.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeFlow computes percentiles for all of a report's cards, followed by each label group.
+func summarizeFlow(r *SprintReport, flows []CardFlow) []FlowSummary {
+	byCard := make(map[string]CardFlow, len(flows))
+	for _, flow := range flows {
+		byCard[flow.Card.ID] = flow
+	}
+
+	summarize := func(label string, cards []Card) FlowSummary {
+		var lead, cycle []time.Duration
+		for _, card := range cards {
+			flow, ok := byCard[card.ID]
+			if !ok {
+				continue
+			}
+
+			lead = append(lead, flow.LeadTime())
+			if d, ok := flow.CycleTime(); ok {
+				cycle = append(cycle, d)
+			}
+		}
+
+		return FlowSummary{Label: label, Lead: percentiles(lead), Cycle: percentiles(cycle)}
+	}
+
+	summaries := []FlowSummary{summarize("All cards", r.Cards)}
+	for _, group := range r.Groups() {
+		summaries = append(summaries, summarize(group.Label, group.Cards))
+	}
+	return summaries
+}
+
+// percentiles uses the nearest-rank method to find the 50th, 85th and 95th percentiles.
+func percentiles(durations []time.Duration) Percentiles {
+	p := Percentiles{N: len(durations)}
+	if p.N == 0 {
+		return p
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Sort(byDuration(sorted))
+
+	rank := func(pct float64) time.Duration {
+		i := int(math.Ceil(pct/100*float64(p.N))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return sorted[i]
+	}
+
+	p.P50 = rank(50)
+	p.P85 = rank(85)
+	p.P95 = rank(95)
+	return p
+}
+
+type byDuration []time.Duration
+
+func (s byDuration) Len() int           { return len(s) }
+func (s byDuration) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byDuration) Less(i, j int) bool { return s[i] < s[j] }
+
+// formatDays renders a duration in days.
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+// String renders the percentiles compactly, like "2.0d / 4.5d / 6.0d".
+func (p Percentiles) String() string {
+	if p.N == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s / %s", formatDays(p.P50), formatDays(p.P85), formatDays(p.P95))
+}
+
+// addFlow measures the cycle and lead times of a report's cards and attaches their summary.
+func addFlow(conn Connection, p *Profile, inProgress []Ref, r *SprintReport, end time.Time) error {
+	flows, err := measureFlow(conn, inProgress, p.DoneList, r.Cards, end)
+	if err != nil {
+		return err
+	}
+
+	r.Flow = summarizeFlow(r, flows)
+	return nil
+}
+
+func flow(c *cli.Context) {
+	p, conn := setup(c)
+
+	inProgress, err := p.Flow.inProgressRefs()
+	handleErr(err)
+
+	board, err := findArchiveBoard(conn, c.Args().First())
+	handleErr(err)
+
+	doneList, err := conn.FindList(p.DoneList, board.ID)
+	handleErr(err)
+
+	report, err := buildSprintReport(conn, board, board.Name, doneList.ID, nil, sprintLabelFor(board.Name))
+	handleErr(err)
+
+	end, ok := sprintEndFor(board.Name)
+	if !ok {
+		end = time.Now()
+	}
+
+	err = addFlow(conn, p, inProgress, report, end)
+	handleErr(err)
+
+	fmt.Printf("%s: %d cards. Percentiles are p50 / p85 / p95.\n\n", board.Name, len(report.Cards))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tCARDS\tLEAD TIME\tCYCLE TIME")
+	for _, summary := range report.Flow {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", summary.Label, summary.Lead.N, summary.Lead, summary.Cycle)
+	}
+	w.Flush()
+}
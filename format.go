@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formatter renders a sprint report in one output format.
+type Formatter interface {
+	Format(w io.Writer, r *SprintReport) error
+}
+
+var formatters = map[string]Formatter{
+	"markdown": MarkdownFormatter{},
+	"html":     HTMLFormatter{},
+	"csv":      CSVFormatter{},
+	"json":     JSONFormatter{},
+}
+
+// RegisterFormatter makes a Formatter available by name to commands that accept a --format flag.
+func RegisterFormatter(name string, f Formatter) {
+	formatters[strings.ToLower(name)] = f
+}
+
+// FindFormatter returns the Formatter registered under a name.
+func FindFormatter(name string) (Formatter, error) {
+	f, ok := formatters[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown format [%s]. Choose one of: %s.", name, strings.Join(formatterNames(), ", "))
+	}
+	return f, nil
+}
+
+func formatterNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MarkdownFormatter renders a report as a Markdown document, suitable for a wiki.
+type MarkdownFormatter struct{}
+
+// Format implements Formatter.
+func (MarkdownFormatter) Format(w io.Writer, r *SprintReport) error {
+	groups := r.Groups()
+
+	fmt.Fprintf(w, "# %s\n\n", r.Name)
+	if r.BoardURL != "" {
+		fmt.Fprintf(w, "[Archive board](%s)\n\n", r.BoardURL)
+	}
+	fmt.Fprintf(w, "**%d cards completed, %s points.**\n", len(r.Cards), formatPoints(r.TotalPoints()))
+
+	for _, group := range groups {
+		fmt.Fprintf(w, "\n## %s (%d)\n\n", group.Label, len(group.Cards))
+
+		for _, card := range group.Cards {
+			fmt.Fprintf(w, "- [%s](%s)", markdownEscape(card.Name), card.ShortURL)
+			if points := r.CardPoints(card); points != 0 {
+				fmt.Fprintf(w, " **%s pts**", formatPoints(points))
+			}
+			if labels := r.Labels(card); len(labels) > 0 {
+				fmt.Fprintf(w, " _%s_", strings.Join(labels, ", "))
+			}
+			if len(card.Members) > 0 {
+				handles := make([]string, 0, len(card.Members))
+				for _, member := range card.Members {
+					handles = append(handles, "@"+member.Username)
+				}
+				fmt.Fprintf(w, " %s", strings.Join(handles, " "))
+			}
+			fmt.Fprintln(w)
+
+			if e := excerpt(card.Desc); e != "" {
+				fmt.Fprintf(w, "  > %s\n", e)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Totals\n\n| Label | Cards | Points |\n| --- | ---: | ---: |\n")
+	for _, group := range groups {
+		fmt.Fprintf(w, "| %s | %d | %s |\n", group.Label, len(group.Cards), formatPoints(r.GroupPoints(group)))
+	}
+	_, err := fmt.Fprintf(w, "| **Total** | **%d** | **%s** |\n", len(r.Cards), formatPoints(r.TotalPoints()))
+
+	if len(r.Flow) > 0 {
+		fmt.Fprintf(w, "\n## Flow\n\nPercentiles are p50 / p85 / p95.\n\n")
+		fmt.Fprintf(w, "| Group | Cards | Lead time | Cycle time |\n| --- | ---: | ---: | ---: |\n")
+		for _, summary := range r.Flow {
+			_, err = fmt.Fprintf(w, "| %s | %d | %s | %s |\n", summary.Label, summary.Lead.N, summary.Lead, summary.Cycle)
+		}
+	}
+	return err
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"excerpt": excerpt,
+	"join":    strings.Join,
+	"points":  formatPoints,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Report.Name}}</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; color: #172b4d; max-width: 48em; margin: 2em auto; }
+h2 { border-bottom: 1px solid #dfe1e6; padding-bottom: 0.2em; }
+li { margin-bottom: 0.5em; }
+.labels { font-style: italic; color: #5e6c84; }
+.members { color: #0079bf; }
+.excerpt { display: block; color: #5e6c84; font-size: 0.9em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #dfe1e6; padding: 0.3em 0.8em; }
+td.count { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Report.Name}}</h1>
+{{if .Report.BoardURL}}<p><a href="{{.Report.BoardURL}}">Archive board</a></p>{{end}}
+<p><strong>{{len .Report.Cards}} cards completed, {{points .Report.TotalPoints}} points.</strong></p>
+{{range .Groups}}
+<h2>{{.Label}} ({{len .Cards}})</h2>
+<ul>
+{{range .Cards}}<li><a href="{{.ShortURL}}">{{.Name}}</a>
+{{with $.Report.CardPoints .}}<strong>{{points .}} pts</strong>{{end}}
+{{with $.Report.Labels .}}<span class="labels">{{join . ", "}}</span>{{end}}
+{{range .Members}}<span class="members">@{{.Username}}</span> {{end}}
+{{with excerpt .Desc}}<span class="excerpt">{{.}}</span>{{end}}</li>
+{{end}}</ul>
+{{end}}
+<h2>Totals</h2>
+<table>
+<tr><th>Label</th><th>Cards</th><th>Points</th></tr>
+{{range .Groups}}<tr><td>{{.Label}}</td><td class="count">{{len .Cards}}</td><td class="count">{{points ($.Report.GroupPoints .)}}</td></tr>
+{{end}}<tr><th>Total</th><th class="count">{{len .Report.Cards}}</th><th class="count">{{points .Report.TotalPoints}}</th></tr>
+</table>
+{{if .Report.Flow}}
+<h2>Flow</h2>
+<p>Percentiles are p50 / p85 / p95.</p>
+<table>
+<tr><th>Group</th><th>Cards</th><th>Lead time</th><th>Cycle time</th></tr>
+{{range .Report.Flow}}<tr><td>{{.Label}}</td><td class="count">{{.Lead.N}}</td><td class="count">{{.Lead}}</td><td class="count">{{.Cycle}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// HTMLFormatter renders a report as a self-contained HTML page, suitable for email.
+type HTMLFormatter struct{}
+
+// Format implements Formatter.
+func (HTMLFormatter) Format(w io.Writer, r *SprintReport) error {
+	return htmlReport.Execute(w, struct {
+		Report *SprintReport
+		Groups []LabelGroup
+	}{r, r.Groups()})
+}
+
+// CSVFormatter renders a report with one row per card, suitable for a spreadsheet.
+type CSVFormatter struct{}
+
+// Format implements Formatter.
+func (CSVFormatter) Format(w io.Writer, r *SprintReport) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"Sprint", "Card", "URL", "Points", "Labels", "Members", "Description"})
+	for _, card := range r.Cards {
+		usernames := make([]string, 0, len(card.Members))
+		for _, member := range card.Members {
+			usernames = append(usernames, member.Username)
+		}
+
+		cw.Write([]string{
+			r.Name,
+			card.Name,
+			card.ShortURL,
+			formatPoints(r.CardPoints(card)),
+			strings.Join(r.Labels(card), "; "),
+			strings.Join(usernames, "; "),
+			excerpt(card.Desc),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// JSONFormatter renders a report as a JSON document, suitable for scripts.
+type JSONFormatter struct{}
+
+type jsonCard struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Points      float64  `json:"points"`
+	Labels      []string `json:"labels"`
+	Members     []string `json:"members"`
+	Description string   `json:"description"`
+}
+
+type jsonFlow struct {
+	Label string      `json:"label"`
+	Lead  jsonPercent `json:"lead_days"`
+	Cycle jsonPercent `json:"cycle_days"`
+}
+
+type jsonPercent struct {
+	N   int     `json:"n"`
+	P50 float64 `json:"p50"`
+	P85 float64 `json:"p85"`
+	P95 float64 `json:"p95"`
+}
+
+func newJSONPercent(p Percentiles) jsonPercent {
+	days := func(d time.Duration) float64 { return d.Hours() / 24 }
+	return jsonPercent{N: p.N, P50: days(p.P50), P85: days(p.P85), P95: days(p.P95)}
+}
+
+type jsonReport struct {
+	Sprint   string         `json:"sprint"`
+	BoardURL string         `json:"board_url"`
+	Total    int            `json:"total"`
+	Points   float64        `json:"points"`
+	ByLabel  map[string]int `json:"by_label"`
+	Cards    []jsonCard     `json:"cards"`
+	Flow     []jsonFlow     `json:"flow,omitempty"`
+}
+
+// Format implements Formatter.
+func (JSONFormatter) Format(w io.Writer, r *SprintReport) error {
+	doc := jsonReport{
+		Sprint:   r.Name,
+		BoardURL: r.BoardURL,
+		Total:    len(r.Cards),
+		Points:   r.TotalPoints(),
+		ByLabel:  make(map[string]int),
+		Cards:    make([]jsonCard, 0, len(r.Cards)),
+	}
+
+	for _, group := range r.Groups() {
+		doc.ByLabel[group.Label] = len(group.Cards)
+	}
+
+	for _, card := range r.Cards {
+		usernames := make([]string, 0, len(card.Members))
+		for _, member := range card.Members {
+			usernames = append(usernames, member.Username)
+		}
+
+		labels := r.Labels(card)
+		if labels == nil {
+			labels = []string{}
+		}
+
+		doc.Cards = append(doc.Cards, jsonCard{
+			ID:          card.ID,
+			Name:        card.Name,
+			URL:         card.ShortURL,
+			Points:      r.CardPoints(card),
+			Labels:      labels,
+			Members:     usernames,
+			Description: card.Desc,
+		})
+	}
+
+	for _, summary := range r.Flow {
+		doc.Flow = append(doc.Flow, jsonFlow{
+			Label: summary.Label,
+			Lead:  newJSONPercent(summary.Lead),
+			Cycle: newJSONPercent(summary.Cycle),
+		})
+	}
+
+	enc, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", enc)
+	return err
+}
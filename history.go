@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// parseDateFlag reads an optional YYYY-MM-DD flag.
+func parseDateFlag(c *cli.Context, name string) (time.Time, error) {
+	value := c.String(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("The --%s flag must be a date like 2026-10-16.", name)
+	}
+	return t, nil
+}
+
+func history(c *cli.Context) {
+	p, conn := setup(c)
+
+	from, err := parseDateFlag(c, "from")
+	handleErr(err)
+
+	to, err := parseDateFlag(c, "to")
+	handleErr(err)
+
+	sprints, err := findArchivedSprints(conn)
+	handleErr(err)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "SPRINT\tCARDS\tPOINTS\tSTATUS\tCLOSED BY\tLINK")
+
+	shown := 0
+	for _, sprint := range sprints {
+		if !from.IsZero() && sprint.End.Before(from) {
+			continue
+		}
+		if !to.IsZero() && sprint.End.After(to) {
+			continue
+		}
+
+		cards, points := "?", "?"
+		if v, err := measureVelocity(conn, p, sprint); err == nil {
+			cards = fmt.Sprintf("%d", v.Cards)
+			points = "-"
+			if v.Points != 0 {
+				points = formatPoints(v.Points)
+			}
+		} else {
+			log.WithFields(log.Fields{
+				"board name": sprint.Name,
+				"error":      err,
+			}).Debug("Unable to count completed cards.")
+		}
+
+		status := "open"
+		if sprint.Closed {
+			status = "closed"
+		}
+
+		closedBy := "?"
+		if creator, err := conn.FindBoardCreator(sprint.ID); err != nil {
+			log.WithFields(log.Fields{
+				"board name": sprint.Name,
+				"error":      err,
+			}).Debug("Unable to find who closed the sprint.")
+		} else if creator != nil {
+			closedBy = Contributor{Member: *creator}.DisplayName()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			sprint.End.Format("2006-01-02"), cards, points, status, closedBy, sprint.URL)
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Println("No archived sprints found.")
+		return
+	}
+	w.Flush()
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// authorizeURL is where a user generates a token for the key.
+const authorizeURL = "https://trello.com/1/authorize?key=%s&name=Closer&expiration=never&scope=read,write&response_type=token"
+
+// prompter asks the user for the values that init needs.
+type prompter struct {
+	in          *bufio.Reader
+	interactive bool
+}
+
+// ask returns value if it is set. Otherwise, it prints the question and reads a line of input.
+func (p prompter) ask(value, flag, question string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+
+	if !p.interactive {
+		return "", fmt.Errorf("Missing --%s.", flag)
+	}
+
+	fmt.Printf("%s ", question)
+	line, err := p.in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" && err != nil {
+		return "", err
+	}
+	return line, nil
+}
+
+// openBrowser tries to show a URL in the user's browser. It's fine if it can't.
+func openBrowser(u string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	cmd.Start()
+}
+
+// chooseOrg picks one of the user's organizations, asking if there's more than one.
+func chooseOrg(p prompter, orgs []OrgSummary) (string, error) {
+	switch len(orgs) {
+	case 0:
+		return "", errors.New("You don't belong to any Trello organizations.")
+	case 1:
+		fmt.Printf("Using your only organization, %s (%s).\n", orgs[0].DisplayName, orgs[0].Name)
+		return orgs[0].Name, nil
+	}
+
+	if !p.interactive {
+		return "", errors.New("Missing --organization.")
+	}
+
+	fmt.Println("Your organizations:")
+	for i, org := range orgs {
+		fmt.Printf("  %d. %s (%s)\n", i+1, org.DisplayName, org.Name)
+	}
+
+	answer, err := p.ask("", "organization", "Organization number:")
+	if err != nil {
+		return "", err
+	}
+
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(orgs) {
+		return "", fmt.Errorf("[%s] is not one of the organizations listed.", answer)
+	}
+	return orgs[n-1].Name, nil
+}
+
+// profileExists returns true if the profile file already has a key for the named profile.
+func profileExists(path, name string) bool {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return false
+	}
+
+	if name != "" {
+		profiles, _ := settings["profiles"].(map[string]interface{})
+		if _, ok := profiles[name]; !ok {
+			return false
+		}
+	}
+
+	settings, err = selectProfile(settings, name)
+	return err == nil && settings["key"] != nil
+}
+
+// targetProfile names the profile that init writes to: the one given with --profile-name, or else
+// the file's default_profile. It's empty if the settings belong at the top level.
+func targetProfile(path, name string) string {
+	if name != "" {
+		return name
+	}
+
+	settings, err := readSettings(path)
+	if err != nil || settings == nil {
+		return ""
+	}
+
+	name, _ = settings["default_profile"].(string)
+	return name
+}
+
+// existingTokenSource copies the token_command, token_file or token_keyring that the named profile
+// already uses, if any, into p.
+func existingTokenSource(path, name string, p *Profile) {
+	settings, err := readSettings(path)
+	if err != nil || settings == nil {
+		return
+	}
+
+	settings, err = selectProfile(settings, name)
+	if err != nil {
+		return
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return
+	}
+
+	var existing Profile
+	if err := json.Unmarshal(b, &existing); err != nil {
+		return
+	}
+
+	p.TokenCommand = existing.TokenCommand
+	p.TokenFile = existing.TokenFile
+	p.TokenKeyring = existing.TokenKeyring
+}
+
+// writeProfile stores the key, token and organization at path, keeping any other settings that are
+// already there. If name is set, they're stored in the named profile instead of at the top level.
+// The file is only readable by its owner.
+func writeProfile(path, name string, p *Profile) error {
+	settings := make(map[string]interface{})
+	if b, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(b, &settings); err != nil {
+			return fmt.Errorf("Unable to read the existing profile [%s]: %v", path, err)
+		}
+	}
+
+	target := settings
+	if name != "" {
+		profiles, ok := settings["profiles"].(map[string]interface{})
+		if !ok {
+			profiles = make(map[string]interface{})
+			settings["profiles"] = profiles
+		}
+
+		target, ok = profiles[name].(map[string]interface{})
+		if !ok {
+			target = make(map[string]interface{})
+			profiles[name] = target
+		}
+	}
+
+	target["key"] = p.Key
+	target["organization"] = p.Organization
+
+	if hasTokenSource(p) {
+		// A plaintext token would take precedence over the secret source.
+		for _, key := range []string{"token", "token_command", "token_file", "token_keyring"} {
+			delete(target, key)
+		}
+
+		switch {
+		case p.TokenCommand != "":
+			target["token_command"] = p.TokenCommand
+		case p.TokenFile != "":
+			target["token_file"] = p.TokenFile
+		default:
+			target["token_keyring"] = p.TokenKeyring
+		}
+	} else {
+		target["token"] = p.Token
+	}
+
+	b, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+func initProfile(c *cli.Context) {
+	setLogLevel(c)
+
+	path := c.GlobalString("profile")
+	name := targetProfile(path, c.GlobalString("profile-name"))
+	if profileExists(path, name) && !c.Bool("force") {
+		handleErr(fmt.Errorf("A profile already exists at %s. Use --force to replace its key, token and organization.", path))
+	}
+
+	p := prompter{in: bufio.NewReader(os.Stdin), interactive: !c.Bool("non-interactive")}
+	profile := &Profile{}
+
+	var err error
+	if c.String("key") == "" && p.interactive {
+		fmt.Println("Log in to Trello and find your API key at https://trello.com/1/appKey/generate")
+	}
+	profile.Key, err = p.ask(c.String("key"), "key", "Key:")
+	handleErr(err)
+
+	profile.TokenCommand = c.String("token-command")
+	profile.TokenFile = c.String("token-file")
+	if c.String("token") == "" && !hasTokenSource(profile) {
+		existingTokenSource(path, name, profile)
+	}
+
+	if c.String("token") == "" && hasTokenSource(profile) {
+		err = resolveToken(profile)
+		handleErr(err)
+
+		fmt.Printf("Read the token from the %s.\n", profile.tokenSource)
+	} else {
+		profile.TokenCommand, profile.TokenFile, profile.TokenKeyring = "", "", nil
+
+		if c.String("token") == "" && p.interactive {
+			u := fmt.Sprintf(authorizeURL, profile.Key)
+			fmt.Printf("Allow access and copy the token from:\n\n%s\n\n", u)
+			openBrowser(u)
+		}
+		profile.Token, err = p.ask(c.String("token"), "token", "Token:")
+		handleErr(err)
+	}
+
+	conn := Connection{profile: *profile}
+
+	me, err := conn.FindMe()
+	if err != nil {
+		handleErr(fmt.Errorf("Trello didn't accept the key and token: %s", firstLine(err.Error())))
+	}
+	fmt.Printf("Signed in as %s.\n", me.Username)
+
+	profile.Organization = c.String("organization")
+	if profile.Organization == "" {
+		orgs, err := conn.ListMyOrgs()
+		handleErr(err)
+
+		profile.Organization, err = chooseOrg(p, orgs)
+		handleErr(err)
+	}
+
+	conn = Connection{profile: *profile}
+	org, err := conn.FindOrg()
+	if err != nil {
+		handleErr(fmt.Errorf("Unable to find the organization [%s]: %s", profile.Organization, firstLine(err.Error())))
+	}
+
+	if _, ok := org.Member(me.ID); !ok {
+		handleErr(fmt.Errorf("%s is not a member of the organization [%s].", me.Username, profile.Organization))
+	}
+
+	err = writeProfile(path, name, profile)
+	handleErr(err)
+
+	fmt.Printf("Wrote %s. Run \"sprint-closer doctor\" to check the rest of your setup.\n", path)
+}
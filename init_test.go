@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testProfilePath writes raw to a profile file in a temporary directory.
+func testProfilePath(t *testing.T, raw string) (string, func()) {
+	dir, err := ioutil.TempDir("", "sprint-closer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "profile.json")
+	if raw != "" {
+		if err := ioutil.WriteFile(path, []byte(raw), 0600); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func readTestProfile(t *testing.T, path string) map[string]interface{} {
+	settings, err := readSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
+
+func TestWriteProfile(t *testing.T) {
+	path, cleanup := testProfilePath(t, `{"key": "old", "token": "old", "sprint_board": "Sprint"}`)
+	defer cleanup()
+
+	p := &Profile{Key: "k", Token: "t", Organization: "devex"}
+	if err := writeProfile(path, "", p); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readTestProfile(t, path)
+	want := testSettings(t, `{"key": "k", "token": "t", "organization": "devex", "sprint_board": "Sprint"}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrote %v, want %v", got, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode = %v, want 0600", mode)
+	}
+}
+
+func TestWriteProfileTokenSource(t *testing.T) {
+	path, cleanup := testProfilePath(t, `{"profiles": {"work": {"token": "plaintext", "token_file": "old"}}}`)
+	defer cleanup()
+
+	p := &Profile{Key: "k", Token: "t", Organization: "devex", TokenCommand: "pass trello"}
+	if err := writeProfile(path, "work", p); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readTestProfile(t, path)
+	want := testSettings(t, `{"profiles": {"work": {"key": "k", "organization": "devex", "token_command": "pass trello"}}}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrote %v, want %v", got, want)
+	}
+}
+
+func TestWriteDefaultProfile(t *testing.T) {
+	path, cleanup := testProfilePath(t, `{"default_profile": "work", "profiles": {"work": {"sprint_board": "Work Sprint"}}}`)
+	defer cleanup()
+
+	name := targetProfile(path, "")
+	if name != "work" {
+		t.Fatalf("target profile = %q, want \"work\"", name)
+	}
+	if profileExists(path, name) {
+		t.Errorf("a profile without a key should not count as existing")
+	}
+
+	p := &Profile{Key: "k", Token: "t", Organization: "devex"}
+	if err := writeProfile(path, name, p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadProfile(path, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Key != "k" || loaded.Token != "t" || loaded.Organization != "devex" || loaded.SprintBoard != "Work Sprint" {
+		t.Errorf("loaded %+v", loaded)
+	}
+
+	got := readTestProfile(t, path)
+	if _, ok := got["key"]; ok {
+		t.Errorf("wrote the key at the top level: %v", got)
+	}
+	if !profileExists(path, name) {
+		t.Errorf("the default profile should exist once written")
+	}
+
+	if name := targetProfile(path, "other"); name != "other" {
+		t.Errorf("target profile = %q, want \"other\"", name)
+	}
+}
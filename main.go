@@ -24,103 +24,373 @@ func main() {
 			Usage: "Logging level",
 		},
 		cli.StringFlag{
-			Name:  "profile, p",
-			Value: path.Join(os.Getenv("HOME"), ".trello.json"),
-			Usage: "Path to a JSON profile.",
+			Name:   "profile, p",
+			Value:  path.Join(os.Getenv("HOME"), ".trello.json"),
+			Usage:  "Path to a JSON profile.",
+			EnvVar: "SPRINT_CLOSER_PROFILE",
+		},
+		cli.StringFlag{
+			Name:   "profile-name, P",
+			Usage:  "Name of a profile in the profile file. Defaults to its default_profile.",
+			EnvVar: "SPRINT_CLOSER_PROFILE_NAME",
+		},
+		cli.StringFlag{
+			Name:  "organization",
+			Usage: "Trello organization, overriding the profile",
+		},
+		cli.StringFlag{
+			Name:  "sprint-board",
+			Usage: "Sprint board, overriding the profile",
 		},
 	}
 
 	app.Action = run
 
+	app.Commands = []cli.Command{
+		{
+			Name:   "stale",
+			Usage:  "List cards that have been carried over too many times",
+			Action: stale,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "threshold, t",
+					Value: 3,
+					Usage: "Report cards carried over at least this many times",
+				},
+			},
+		},
+		{
+			Name:   "velocity",
+			Usage:  "Report the cards and points completed in each archived sprint",
+			Action: velocity,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "sprints, n",
+					Value: 12,
+					Usage: "Number of recent sprints to include",
+				},
+				cli.IntFlag{
+					Name:  "window, w",
+					Value: 3,
+					Usage: "Number of sprints in the rolling average",
+				},
+			},
+		},
+		{
+			Name:        "contributions",
+			Usage:       "Count the cards and points completed by each member",
+			Description: "Give the sprint as a date (2026-10-16) or an archive board name, or use --sprints for a range of recent sprints.",
+			Action:      contributions,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "sprints, n",
+					Usage: "Total the most recent archived sprints instead of a single one",
+				},
+			},
+		},
+		{
+			Name:        "flow",
+			Usage:       "Report cycle and lead time percentiles for an archived sprint",
+			Description: "Give the sprint as a date (2026-10-16) or an archive board name. Defaults to the most recent sprint.",
+			Action:      flow,
+		},
+		{
+			Name:   "history",
+			Usage:  "List the archived sprints and what was finished in each",
+			Action: history,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "Only list sprints that ended on or after this date (2026-08-01)",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Usage: "Only list sprints that ended on or before this date (2026-08-31)",
+				},
+			},
+		},
+		{
+			Name:        "release-notes",
+			Usage:       "Turn the completed cards of an archived sprint into release notes",
+			Description: "Give the sprint as a date (2026-10-16) or an archive board name. Defaults to the most recent sprint.",
+			Action:      releaseNotes,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "template, t",
+					Usage: "Path to a Go template for the release notes",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Write the release notes to a file instead of stdout",
+				},
+			},
+		},
+		{
+			Name:        "init",
+			Usage:       "Create a profile with your Trello key, token and organization",
+			Description: "Anything that isn't given as a flag is asked for. Use --non-interactive in scripts.",
+			Action:      initProfile,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "key",
+					Usage: "Trello API key",
+				},
+				cli.StringFlag{
+					Name:  "token",
+					Usage: "Trello token with read and write scope",
+				},
+				cli.StringFlag{
+					Name:  "token-command",
+					Usage: "Shell command that prints the token, stored instead of the token itself",
+				},
+				cli.StringFlag{
+					Name:  "token-file",
+					Usage: "File that holds the token, stored instead of the token itself",
+				},
+				cli.StringFlag{
+					Name:  "organization",
+					Usage: "Name of the Trello organization",
+				},
+				cli.BoolFlag{
+					Name:  "non-interactive",
+					Usage: "Fail instead of asking for anything that's missing",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Replace the key, token and organization in an existing profile",
+				},
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "Inspect the resolved configuration",
+			Subcommands: []cli.Command{
+				{
+					Name:   "show",
+					Usage:  "Print each setting, with secrets masked, and where it came from",
+					Action: configShow,
+				},
+			},
+		},
+		{
+			Name:   "doctor",
+			Usage:  "Check the profile, credentials and boards for setup problems",
+			Action: doctor,
+		},
+		{
+			Name:        "restore",
+			Usage:       "Recreate the lists, cards, labels and checklists in a board backup",
+			Description: "Give the path to a backup written during a close. The changes are listed before anything is created.",
+			Action:      restore,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "board, b",
+					Usage: "Board to restore onto. Defaults to the sprint board",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run, n",
+					Usage: "Only list what would be created",
+				},
+			},
+		},
+		{
+			Name:        "report",
+			Usage:       "Print the report for an archived sprint",
+			Description: "Give the sprint as a date (2026-10-16) or an archive board name. Defaults to the most recent sprint.",
+			Action:      showReport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "markdown",
+					Usage: "Output format: markdown, html, csv or json",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Write the report to a file instead of stdout",
+				},
+				cli.BoolFlag{
+					Name:  "flow",
+					Usage: "Include cycle and lead times, which reads each card's history",
+				},
+			},
+		},
+	}
+
 	app.Run(os.Args)
 }
 
-func run(c *cli.Context) {
-	levelName := strings.ToLower(c.String("log"))
-	level, err := log.ParseLevel(levelName)
-	handleErr(err)
-	log.SetLevel(level)
+// setup configures logging and loads the profile selected by the global flags.
+func setup(c *cli.Context) (*Profile, Connection) {
+	setLogLevel(c)
 
-	p, err := LoadProfile(c.String("profile"))
+	p, err := LoadProfile(c.GlobalString("profile"), c.GlobalString("profile-name"), flagSettings(c))
 	handleErr(err)
 
-	conn := Connection{profile: *p}
+	return p, Connection{profile: *p}
+}
 
-	currentSprintID, err := conn.FindBoard("Current Sprint")
+// setLogLevel applies the logging level chosen by the global flags.
+func setLogLevel(c *cli.Context) {
+	levelName := strings.ToLower(c.GlobalString("log"))
+	level, err := log.ParseLevel(levelName)
 	handleErr(err)
+	log.SetLevel(level)
+}
 
-	log.WithField("board id", currentSprintID).Debug("Current sprint board located.")
+func run(c *cli.Context) {
+	p, conn := setup(c)
 
-	doneList, err := conn.FindList("Done", currentSprintID)
+	plan, err := preflight(conn, p)
 	handleErr(err)
 
-	log.WithField("list id", doneList.ID).Debug("Done list located.")
-
-	org, err := conn.FindOrg()
-	handleErr(err)
+	currentSprintID, archiveLists, doneList := plan.boardID, plan.archiveLists, plan.doneList
+	org, myID := plan.org, plan.myID
 
 	log.WithFields(log.Fields{
+		"board id":     currentSprintID,
 		"org id":       org.ID,
-		"member count": len(org.MemberIDs),
-	}).Debug("Organization ID located.")
+		"member count": len(org.Members),
+		"user id":      myID,
+	}).Debug("Current sprint board, organization and user located.")
+
+	history, err := rebuildListHistory(conn, currentSprintID, sprintEnd(), p.SprintDays)
+	handleErr(err)
+
+	backup, err := takeBackup(conn, currentSprintID)
+	handleErr(err)
 
-	myID, err := conn.FindMyUserID()
+	backupPath, err := writeBackup(p.Backup, backup)
 	handleErr(err)
 
-	log.WithField("user id", myID).Debug("My user ID located.")
+	log.WithField("path", backupPath).Info("Backed up the sprint board.")
 
 	archiveBoardName := newBoardName()
-	archiveBoardID, err := conn.CreateBoard(archiveBoardName)
+	archiveBoard, err := conn.CreateBoard(archiveBoardName)
 	handleErr(err)
 
 	log.WithFields(log.Fields{
-		"board id":   archiveBoardID,
+		"board id":   archiveBoard.ID,
 		"board name": archiveBoardName,
 	}).Info("Created archive board.")
 
-	for _, memberID := range org.MemberIDs {
-		if memberID != myID {
-			log.WithField("member ID", memberID).Debug("Granting access")
-			err = conn.AddMember(archiveBoardID, memberID)
-			handleErr(err)
-		}
-	}
+	err = grantAccess(conn, archiveBoard.ID, org, myID)
+	handleErr(err)
 
-	log.WithField("member count", len(org.MemberIDs)).Info("Granted access to this organization.")
+	log.WithField("member count", len(org.Members)).Info("Granted access to this organization.")
 
-	autoListIDs, err := conn.GetListIDs(archiveBoardID)
+	err = clearLists(conn, archiveBoard.ID)
 	handleErr(err)
 
-	for _, listID := range autoListIDs {
-		log.WithField("list id", listID).Debug("Deleting list")
-		err = conn.DeleteList(listID)
+	log.Info("Deleted pre-existing lists.")
+
+	for i, list := range archiveLists {
+		err = conn.MoveList(list.ID, archiveBoard.ID, i+1)
 		handleErr(err)
+
+		log.WithField("list name", list.Name).Info("Moved list to the archive board.")
+
+		_, err = conn.AddList(list.Name, currentSprintID, list.Position)
+		handleErr(err)
+
+		log.WithField("list name", list.Name).Info("Created empty list on the Current Sprint board.")
 	}
 
-	log.Info("Deleted pre-existing lists.")
+	sprintLabel := sprintLabelName()
+	labeled, err := labelSprintCards(conn, archiveBoard.ID, doneList.ID, sprintLabel)
+	handleErr(err)
+
+	log.WithFields(log.Fields{
+		"label":      sprintLabel,
+		"card count": labeled,
+	}).Info("Labeled completed cards with the sprint.")
+
+	points, err := newPointsReader(conn, p.Points, archiveBoard.ID)
+	handleErr(err)
+
+	report, err := buildSprintReport(conn, archiveBoard, archiveBoardName, doneList.ID, points, sprintLabel)
+	handleErr(err)
+
+	err = addFlow(conn, p, plan.inProgress, report, time.Now())
+	handleErr(err)
 
-	err = conn.MoveList(doneList.ID, archiveBoardID, 1)
+	reportPath, err := writeReport(p.Report.Directory, archiveBoardName, report)
 	handleErr(err)
 
-	log.Info("Moved Done list to the archive board.")
-	err = conn.AddList("Done", currentSprintID, doneList.Position)
+	log.WithField("path", reportPath).Info("Wrote the sprint report.")
+
+	chartPaths, err := writeCharts(p.Report.Directory, archiveBoardName, history, p.DoneList)
 	handleErr(err)
 
-	log.Info("Created Done list on the Current Sprint board.")
+	log.WithField("paths", strings.Join(chartPaths, ", ")).Info("Wrote the sprint charts.")
+
+	err = postReport(conn, p.Report, archiveBoard.ID, report)
+	handleErr(err)
+
+	slipped, err := plan.carryOver.execute(conn)
+	handleErr(err)
+
+	if len(slipped) > 0 {
+		log.WithField("card count", len(slipped)).Info("Carried over unfinished cards.")
+	}
+
+	err = recordCarryOver(conn, archiveBoard.ID, len(archiveLists)+1, slipped)
+	handleErr(err)
+
+	copied, err := plan.actionItems.execute(conn)
+	handleErr(err)
+
+	if copied > 0 {
+		log.WithField("card count", copied).Info("Carried forward the retrospective action items.")
+	}
+
+	retro, err := scaffoldRetro(conn, p.Retro, archiveBoard.ID, len(archiveLists)+2, org, myID)
+	handleErr(err)
+
+	if retro != nil {
+		log.WithField("board id", retro.BoardID).Info("Created the retrospective lists.")
+	}
+
+	if failed := notifyAll(plan.notifiers, summarize(report, slipped, org)); failed > 0 {
+		handleErr(fmt.Errorf("The sprint was closed, but %d of %d notifications failed.", failed, len(plan.notifiers)))
+	}
 }
 
-func handleErr(err error) {
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+// grantAccess adds every member of the organization other than ourselves to a board.
+func grantAccess(conn Connection, boardID string, org *Org, myID string) error {
+	for _, member := range org.Members {
+		if member.ID != myID {
+			log.WithFields(log.Fields{
+				"member ID": member.ID,
+				"username":  member.Username,
+			}).Debug("Granting access")
+
+			if err := conn.AddMember(boardID, member.ID); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
 }
 
-func newBoardName() string {
-	n := time.Now()
+// clearLists deletes the lists that Trello adds to a new board.
+func clearLists(conn Connection, boardID string) error {
+	listIDs, err := conn.GetListIDs(boardID)
+	if err != nil {
+		return err
+	}
 
-	daysUntilFriday := (time.Friday - n.Weekday() - 7) % 7
-	friday := n.AddDate(0, 0, int(daysUntilFriday))
+	for _, listID := range listIDs {
+		log.WithField("list id", listID).Debug("Deleting list")
+		if err := conn.DeleteList(listID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return fmt.Sprintf("DevEx Sprint %s", friday.Format("2006-01-02"))
+func handleErr(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// topContributorCount is the number of contributors that a summary names.
+const topContributorCount = 3
+
+// NotifyConfig lists the places that are told about each successful close.
+type NotifyConfig struct {
+	Webhook WebhookConfig `json:"webhook"`
+	Email   EmailConfig   `json:"email"`
+}
+
+// Notifier announces a closed sprint somewhere.
+type Notifier interface {
+	Notify(s *SprintSummary) error
+}
+
+// SprintSummary is what notifiers announce after a successful close.
+type SprintSummary struct {
+	Sprint       string
+	BoardURL     string
+	CardsDone    int
+	Points       float64
+	Contributors []Contributor
+	CarriedOver  []Card
+	Report       *SprintReport
+}
+
+// summarize condenses a sprint report and the cards that slipped into a SprintSummary. The org,
+// if given, is used to name the contributors.
+func summarize(r *SprintReport, slipped []Card, org *Org) *SprintSummary {
+	contributors := tallyContributions(r.Cards, r.CardPoints, org)
+	if len(contributors) > topContributorCount {
+		contributors = contributors[:topContributorCount]
+	}
+
+	return &SprintSummary{
+		Sprint:       r.Name,
+		BoardURL:     r.BoardURL,
+		CardsDone:    len(r.Cards),
+		Points:       r.TotalPoints(),
+		Contributors: contributors,
+		CarriedOver:  slipped,
+		Report:       r,
+	}
+}
+
+// notifiers creates a Notifier for each configured destination.
+func notifiers(config NotifyConfig) ([]Notifier, error) {
+	var ns []Notifier
+
+	if config.Webhook.URL != "" {
+		n, err := NewWebhookNotifier(config.Webhook, nil)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+
+	if config.Email.Host != "" {
+		n, err := NewEmailNotifier(config.Email)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+
+	return ns, nil
+}
+
+// notifyAll sends a summary through every notifier. A failure is logged rather than stopping the
+// others, because the sprint has already been closed by the time anyone is told about it. It
+// returns the number of notifiers that failed.
+func notifyAll(ns []Notifier, s *SprintSummary) int {
+	failed := 0
+	for _, n := range ns {
+		if err := n.Notify(s); err != nil {
+			log.WithField("error", err).Error("Unable to send a sprint notification.")
+			failed++
+		}
+	}
+	return failed
+}
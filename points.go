@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// defaultPointsPattern matches an estimate in parentheses or brackets, like "(3)" or "[5]".
+const defaultPointsPattern = `[\(\[](\d+(?:\.\d+)?)[\)\]]`
+
+// PointsConfig controls how story points are read from cards.
+type PointsConfig struct {
+	// Pattern is a regular expression matched against card titles. Its first group, or the whole
+	// match if it has no groups, is the estimate. Defaults to numbers like "(3)" or "[5]".
+	Pattern string `json:"pattern"`
+
+	// Field, if set, names a numeric custom field that holds the estimate. It takes precedence over
+	// the title when a card has a value.
+	Field string `json:"field"`
+}
+
+// PointsReader extracts story points from the cards on one board.
+type PointsReader struct {
+	pattern *regexp.Regexp
+	fieldID string
+}
+
+// newPointsReader prepares to read points from the cards on a board. Custom field IDs differ from
+// board to board, so a reader is only valid for the board it was created for.
+func newPointsReader(conn Connection, config PointsConfig, boardID string) (*PointsReader, error) {
+	source := config.Pattern
+	if source == "" {
+		source = defaultPointsPattern
+	}
+
+	pattern, err := regexp.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid story points pattern [%s]: %v", source, err)
+	}
+
+	pr := &PointsReader{pattern: pattern}
+
+	if config.Field != "" {
+		pr.fieldID, err = conn.FindNumberField(boardID, config.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		if pr.fieldID == "" {
+			log.WithFields(log.Fields{
+				"field":    config.Field,
+				"board id": boardID,
+			}).Debug("Story points field not found. Using card titles only.")
+		}
+	}
+
+	return pr, nil
+}
+
+// Points returns the estimate for a card, or zero if it has none.
+func (pr *PointsReader) Points(card Card) float64 {
+	if pr == nil {
+		return 0
+	}
+
+	if pr.fieldID != "" {
+		if n := card.NumberField(pr.fieldID); n != 0 {
+			return n
+		}
+	}
+
+	m := pr.pattern.FindStringSubmatch(card.Name)
+	if m == nil {
+		return 0
+	}
+
+	text := m[0]
+	if len(m) > 1 {
+		text = m[1]
+	}
+
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// formatPoints renders a point total without unnecessary decimals.
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// SprintVelocity is the work completed during one archived sprint.
+type SprintVelocity struct {
+	Sprint ArchivedSprint
+	Cards  int
+	Points float64
+}
+
+// measureVelocity totals the cards and points on the done list of an archived sprint.
+func measureVelocity(conn Connection, p *Profile, sprint ArchivedSprint) (*SprintVelocity, error) {
+	doneList, err := conn.FindList(p.DoneList, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := conn.GetCards(doneList.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := newPointsReader(conn, p.Points, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &SprintVelocity{Sprint: sprint, Cards: len(cards)}
+	for _, card := range cards {
+		v.Points += points.Points(card)
+	}
+	return v, nil
+}
+
+func velocity(c *cli.Context) {
+	p, conn := setup(c)
+	count := c.Int("sprints")
+	window := c.Int("window")
+	if window < 1 {
+		window = 1
+	}
+
+	sprints, err := findArchivedSprints(conn)
+	handleErr(err)
+
+	if count > 0 && len(sprints) > count {
+		sprints = sprints[len(sprints)-count:]
+	}
+
+	if len(sprints) == 0 {
+		fmt.Println("No archived sprints found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "SPRINT\tCARDS\tPOINTS\tAVG(%d)\t\n", window)
+
+	var history []*SprintVelocity
+	for _, sprint := range sprints {
+		v, err := measureVelocity(conn, p, sprint)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"board name": sprint.Name,
+				"error":      err,
+			}).Warn("Skipping sprint.")
+			continue
+		}
+		history = append(history, v)
+
+		start := len(history) - window
+		if start < 0 {
+			start = 0
+		}
+
+		var sum float64
+		for _, each := range history[start:] {
+			sum += each.Points
+		}
+		avg := sum / float64(len(history)-start)
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%.1f\t\n",
+			sprint.End.Format("2006-01-02"), v.Cards, formatPoints(v.Points), avg)
+	}
+	w.Flush()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// closePlan holds everything that preflight located for a close.
+type closePlan struct {
+	boardID      string
+	archiveLists []*List
+	doneList     *List
+	org          *Org
+	myID         string
+	notifiers    []Notifier
+	inProgress   []Ref
+	carryOver    *carryOverPlan
+	actionItems  *actionItemPlan
+}
+
+// preflight checks everything that a close needs before anything is changed, so that a close
+// doesn't stop halfway through. Every problem that it finds is reported together.
+func preflight(conn Connection, p *Profile) (*closePlan, error) {
+	plan := &closePlan{}
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, err := range []error{
+		p.Report.validate(),
+		checkDirectory(p.Report.Directory),
+		checkDirectory(p.Backup.Directory),
+		p.Retro.validate(),
+	} {
+		if err != nil {
+			problem("%v", err)
+		}
+	}
+
+	inProgress, err := p.Flow.inProgressRefs()
+	if err != nil {
+		problem("%v", err)
+	}
+	plan.inProgress = inProgress
+
+	ns, err := notifiers(p.Notify)
+	if err != nil {
+		problem("%v", err)
+	}
+	plan.notifiers = ns
+
+	permissions, err := conn.GetTokenPermissions()
+	if err != nil {
+		problem("Unable to read the token's permissions: %v", err)
+	} else {
+		var read, write bool
+		for _, permission := range permissions {
+			read = read || permission.Read
+			write = write || permission.Write
+		}
+		if !read || !write {
+			problem("The token needs read and write scope. Generate a new one with scope=read,write.")
+		}
+	}
+
+	myID, err := conn.FindMyUserID()
+	if err != nil {
+		problem("Unable to identify the token's user: %v", err)
+	}
+	plan.myID = myID
+
+	org, err := conn.FindOrg()
+	if err != nil {
+		problem("Unable to find the organization [%s]: %v", p.Organization, err)
+	} else if myID != "" {
+		if _, ok := org.Member(myID); !ok {
+			problem("You are not a member of the organization [%s].", p.Organization)
+		}
+	}
+	plan.org = org
+
+	boardID, err := conn.FindBoard(p.SprintBoard)
+	if err != nil {
+		problem("%v", err)
+	} else {
+		plan.boardID = boardID
+
+		memberships, err := conn.GetBoardMemberships(boardID)
+		if err != nil {
+			problem("Unable to read the members of [%s]: %v", p.SprintBoard, err)
+		} else if myID != "" {
+			admin := false
+			for _, membership := range memberships {
+				if membership.MemberID == myID && membership.MemberType == "admin" {
+					admin = true
+				}
+			}
+			if !admin {
+				problem("You are not an admin of the board [%s].", p.SprintBoard)
+			}
+		}
+
+		for _, ref := range p.ArchiveLists {
+			list, err := conn.FindList(ref, boardID)
+			if err != nil {
+				problem("%v", err)
+				continue
+			}
+
+			log.WithFields(log.Fields{
+				"list id":   list.ID,
+				"list name": list.Name,
+			}).Debug("Archive list located.")
+
+			plan.archiveLists = append(plan.archiveLists, list)
+		}
+
+		if len(plan.archiveLists) == len(p.ArchiveLists) {
+			if plan.doneList, err = findDoneList(p.DoneList, plan.archiveLists); err != nil {
+				problem("%v", err)
+			}
+		}
+
+		// The reader used for the report is made once the lists reach the archive board, since
+		// custom field IDs differ between boards. This one only checks the configuration.
+		if _, err := newPointsReader(conn, p.Points, boardID); err != nil {
+			problem("%v", err)
+		}
+
+		if plan.carryOver, err = planCarryOver(conn, p.CarryOver, boardID); err != nil {
+			problem("%v", err)
+		}
+
+		if plan.actionItems, err = planActionItems(conn, p.Retro, boardID); err != nil {
+			problem("%v", err)
+		}
+	}
+
+	boards, err := conn.ListBoards()
+	if err != nil {
+		problem("Unable to list the organization's boards: %v", err)
+	} else {
+		taken := []string{newBoardName()}
+		if p.Retro.Mode == "board" {
+			taken = append(taken, retroBoardName())
+		}
+
+		for _, board := range boards {
+			if containsString(taken, board.Name) {
+				problem("A board named [%s] already exists: %s", board.Name, board.URL)
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		log.Debug("Pre-flight checks passed.")
+		return plan, nil
+	}
+
+	return nil, errors.New("The sprint can't be closed yet:\n  * " + strings.Join(problems, "\n  * "))
+}
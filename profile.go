@@ -3,7 +3,9 @@ package main
 import (
 	"encoding/json"
 	"errors"
-	"os"
+	"fmt"
+	"sort"
+	"strings"
 
 	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
 )
@@ -13,9 +15,57 @@ type Profile struct {
 	Key          string `json:"key"`
 	Token        string `json:"token"`
 	Organization string `json:"organization"`
+
+	// TokenCommand is a shell command that prints the token, like "pass show trello/token".
+	TokenCommand string `json:"token_command"`
+
+	// TokenFile is the path to a file that holds the token.
+	TokenFile string `json:"token_file"`
+
+	// TokenKeyring holds the attributes of a secret in the desktop keyring that contains the token.
+	TokenKeyring map[string]string `json:"token_keyring"`
+
+	// tokenSource describes where the token came from, if it wasn't given directly.
+	tokenSource string
+
+	// SprintBoard identifies the board that holds the current sprint. Defaults to "Current Sprint".
+	SprintBoard string `json:"sprint_board"`
+
+	// SprintDays is the length of a sprint, used to chart its progress. Defaults to 14.
+	SprintDays int `json:"sprint_days"`
+
+	// ArchiveLists identifies the lists that are moved to the archive board, in order. Defaults to just "Done".
+	ArchiveLists []string `json:"archive_lists"`
+
+	// DoneList identifies which of the ArchiveLists holds completed work. Defaults to the first of them.
+	DoneList string `json:"done_list"`
+
+	// CarryOver configures what happens to unfinished cards.
+	CarryOver CarryOver `json:"carry_over"`
+
+	// Report configures the sprint report generated at close time.
+	Report ReportConfig `json:"report"`
+
+	// Points configures how story points are read from cards.
+	Points PointsConfig `json:"points"`
+
+	// Flow configures how cycle and lead times are measured.
+	Flow FlowConfig `json:"flow"`
+
+	// Notify configures where a summary is sent after each close.
+	Notify NotifyConfig `json:"notify"`
+
+	// ReleaseNotes configures the release-notes command.
+	ReleaseNotes ReleaseNotesConfig `json:"release_notes"`
+
+	// Backup configures the copy of the sprint board saved before each close.
+	Backup BackupConfig `json:"backup"`
+
+	// Retro configures the retrospective area created at close time.
+	Retro RetroConfig `json:"retro"`
 }
 
-const noProfileMessage = `Create a file at ~/.trello.json with the following contents:
+const noProfileMessage = `Run "sprint-closer init" to create one, or create a file at ~/.trello.json with the following contents:
 
 {
   "key": "",
@@ -37,32 +87,47 @@ the URL:
 "https://trello.com/automationtesting2" => org name is "automationtesting2"
 `
 
-// LoadProfile attempts to locate and load a profile from disk.
-func LoadProfile(path string) (*Profile, error) {
-	inf, err := os.Open(path)
+// LoadProfile resolves the profile from each configuration layer in turn: the system file, the
+// profile file at path, the environment, and finally overrides from the command line. If the
+// profile file holds named profiles under "profiles", the one called name is used, or its
+// "default_profile" if name is empty.
+func LoadProfile(path, name string, overrides map[string]interface{}) (*Profile, error) {
+	layers, err := loadLayers(path, name, overrides)
 	if err != nil {
-		if os.IsNotExist(err) {
-			log.WithField("profile path", path).Errorf("You have no profile yet!\n%s", noProfileMessage)
-		}
+		return nil, err
+	}
 
+	settings := make(map[string]interface{})
+	for _, layer := range layers {
+		settings = mergeSettings(settings, layer.Settings)
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
 		return nil, err
 	}
-	defer inf.Close()
 
 	p := &Profile{}
-	err = json.NewDecoder(inf).Decode(p)
+	err = json.Unmarshal(b, p)
 	if err != nil {
 		return p, err
 	}
 
+	if p.Key == "" && !hasLayer(layers, profileLayer) {
+		log.WithField("profile path", path).Errorf("You have no profile yet!\n%s", noProfileMessage)
+	}
+
 	if p.Key == "" {
 		log.Error("To get a Trello API key, log in to the web UI and visit: https://trello.com/1/appKey/generate")
 		return p, errors.New("Trello API key missing")
 	}
 
+	if err := resolveToken(p); err != nil {
+		return p, err
+	}
+
 	if p.Token == "" {
-		log.Errorf("To get a Trello token, visit https://trello.com/1/authorize?key=%s&name=Closer"+
-			"&expiration=never&scope=read,write&response_type=token", p.Key)
+		log.Errorf("To get a Trello token, visit "+authorizeURL, p.Key)
 		return p, errors.New("Trello token missing")
 	}
 
@@ -70,5 +135,95 @@ func LoadProfile(path string) (*Profile, error) {
 		return p, errors.New("Trello organization missing")
 	}
 
+	if p.SprintBoard == "" {
+		p.SprintBoard = "Current Sprint"
+	}
+
+	if len(p.ArchiveLists) == 0 {
+		p.ArchiveLists = []string{"Done"}
+	}
+
+	if p.SprintDays <= 0 {
+		p.SprintDays = 14
+	}
+
+	if p.DoneList == "" {
+		p.DoneList = p.ArchiveLists[0]
+	}
+
 	return p, nil
 }
+
+// selectProfile picks one named profile out of a profile file's settings. Each named profile is
+// layered over the settings outside of "profiles", or over the profile named by its "inherits".
+func selectProfile(settings map[string]interface{}, name string) (map[string]interface{}, error) {
+	profiles, _ := settings["profiles"].(map[string]interface{})
+
+	base := make(map[string]interface{})
+	for key, value := range settings {
+		if key != "profiles" && key != "default_profile" {
+			base[key] = value
+		}
+	}
+
+	if name == "" {
+		name, _ = settings["default_profile"].(string)
+	}
+	if name == "" {
+		return base, nil
+	}
+
+	var resolve func(name string, seen []string) (map[string]interface{}, error)
+	resolve = func(name string, seen []string) (map[string]interface{}, error) {
+		if containsString(seen, name) {
+			return nil, fmt.Errorf("The profiles %s inherit from each other.", strings.Join(append(seen, name), " -> "))
+		}
+
+		profile, ok := profiles[name].(map[string]interface{})
+		if !ok {
+			names := make([]string, 0, len(profiles))
+			for each := range profiles {
+				names = append(names, each)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("There is no profile named [%s]. Choose one of: %s.", name, strings.Join(names, ", "))
+		}
+
+		parent := base
+		if inherits, _ := profile["inherits"].(string); inherits != "" {
+			var err error
+			parent, err = resolve(inherits, append(seen, name))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		merged := mergeSettings(parent, profile)
+		delete(merged, "inherits")
+		return merged, nil
+	}
+
+	log.WithField("profile name", name).Debug("Selecting profile")
+	return resolve(name, nil)
+}
+
+// mergeSettings layers one set of JSON settings over another. Objects are merged key by key; any
+// other value replaces the one beneath it.
+func mergeSettings(under, over map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(under)+len(over))
+	for key, value := range under {
+		merged[key] = value
+	}
+
+	for key, value := range over {
+		overObject, overIsObject := value.(map[string]interface{})
+		underObject, underIsObject := merged[key].(map[string]interface{})
+		if overIsObject && underIsObject {
+			merged[key] = mergeSettings(underObject, overObject)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
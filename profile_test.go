@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSettings parses a JSON profile file.
+func testSettings(t *testing.T, raw string) map[string]interface{} {
+	var settings map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
+
+func TestMergeSettings(t *testing.T) {
+	under := testSettings(t, `{"key": "k", "report": {"directory": "reports", "post": "card"}, "archive_lists": ["Done"]}`)
+	over := testSettings(t, `{"report": {"post": "description"}, "archive_lists": ["Shipped"]}`)
+
+	got := mergeSettings(under, over)
+	want := testSettings(t, `{"key": "k", "report": {"directory": "reports", "post": "description"}, "archive_lists": ["Shipped"]}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %v, want %v", got, want)
+	}
+
+	if post := under["report"].(map[string]interface{})["post"]; post != "card" {
+		t.Errorf("merging changed the lower layer: post = %v", post)
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	settings := testSettings(t, `{
+		"key": "k",
+		"organization": "devex",
+		"default_profile": "work",
+		"profiles": {
+			"work": {"sprint_board": "Work Sprint"},
+			"team": {"inherits": "work", "organization": "team"},
+			"a": {"inherits": "b"},
+			"b": {"inherits": "a"}
+		}
+	}`)
+
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"", `{"key": "k", "organization": "devex", "sprint_board": "Work Sprint"}`},
+		{"work", `{"key": "k", "organization": "devex", "sprint_board": "Work Sprint"}`},
+		{"team", `{"key": "k", "organization": "team", "sprint_board": "Work Sprint"}`},
+	}
+
+	for _, c := range cases {
+		got, err := selectProfile(settings, c.name)
+		if err != nil {
+			t.Errorf("%q: %v", c.name, err)
+			continue
+		}
+		if want := testSettings(t, c.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: selected %v, want %v", c.name, got, want)
+		}
+	}
+
+	if _, err := selectProfile(settings, "a"); err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("inheritance loop: err = %v", err)
+	}
+	if _, err := selectProfile(settings, "missing"); err == nil || !strings.Contains(err.Error(), "a, b, team, work") {
+		t.Errorf("missing profile: err = %v", err)
+	}
+
+	got, err := selectProfile(testSettings(t, `{"key": "k", "profiles": {"work": {}}}`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testSettings(t, `{"key": "k"}`); !reflect.DeepEqual(got, want) {
+		t.Errorf("without a default profile: selected %v, want %v", got, want)
+	}
+}
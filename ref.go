@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	idPattern  = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	urlPattern = regexp.MustCompile(`^https?://(?:www\.)?trello\.com/[bc]/([A-Za-z0-9]+)`)
+)
+
+// Ref identifies a Trello board or list. A Ref may be an object ID, a shortLink, a board URL, a
+// case-insensitive name, or a regular expression surrounded by slashes, like "/^sprint \d+$/".
+type Ref struct {
+	raw       string
+	id        string
+	shortLink string
+	pattern   *regexp.Regexp
+}
+
+// ParseRef interprets a user-supplied reference string.
+func ParseRef(raw string) (Ref, error) {
+	r := Ref{raw: raw}
+	trimmed := strings.TrimSpace(raw)
+
+	if idPattern.MatchString(trimmed) {
+		r.id = strings.ToLower(trimmed)
+		return r, nil
+	}
+
+	if m := urlPattern.FindStringSubmatch(trimmed); m != nil {
+		r.shortLink = m[1]
+		return r, nil
+	}
+
+	if len(trimmed) > 2 && strings.HasPrefix(trimmed, "/") && strings.HasSuffix(trimmed, "/") {
+		p, err := regexp.Compile("(?i)" + trimmed[1:len(trimmed)-1])
+		if err != nil {
+			return r, fmt.Errorf("Invalid regular expression in [%s]: %v", raw, err)
+		}
+		r.pattern = p
+		return r, nil
+	}
+
+	// A bare word may be either a name or a shortLink.
+	r.shortLink = trimmed
+	r.pattern = regexp.MustCompile("(?i)^" + regexp.QuoteMeta(trimmed) + "$")
+	return r, nil
+}
+
+// String returns the reference as the user wrote it.
+func (r Ref) String() string {
+	return r.raw
+}
+
+// Matches returns true if an object with the given ID, shortLink and name is identified by this
+// reference. Lists have no shortLink; pass "" for those.
+func (r Ref) Matches(id, shortLink, name string) bool {
+	if r.id != "" {
+		return strings.ToLower(id) == r.id
+	}
+
+	if r.shortLink != "" && shortLink != "" && shortLink == r.shortLink {
+		return true
+	}
+
+	return r.pattern != nil && r.pattern.MatchString(strings.TrimSpace(name))
+}
+
+func ambiguousRefError(kind string, r Ref, names []string) error {
+	return fmt.Errorf("The %s reference [%s] is ambiguous. It matches: %s.", kind, r, strings.Join(names, ", "))
+}
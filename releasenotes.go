@@ -0,0 +1,195 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// pullRequestPattern recognizes links to GitHub pull requests.
+var pullRequestPattern = regexp.MustCompile(`^https://github\.com/[^/]+/[^/]+/pull/\d+`)
+
+// defaultReleaseNotesTemplate renders release notes as Markdown.
+const defaultReleaseNotesTemplate = `# Release notes: {{.Sprint}}
+{{range .Sections}}
+## {{.Title}}
+{{range .Entries}}
+- {{.Name}}{{range .PullRequests}} ({{.}}){{end}}{{end}}
+{{end}}`
+
+// ReleaseNotesConfig controls how a sprint's completed cards become release notes.
+type ReleaseNotesConfig struct {
+	// Sections sort cards by label, in order. A card goes in the first section that lists one of
+	// its labels.
+	Sections []ReleaseSectionConfig `json:"sections"`
+
+	// Other is the title of the section that collects cards matching no other section. Cards
+	// matching no section are left out if it is "-". Defaults to "Other".
+	Other string `json:"other"`
+
+	// Hide lists labels that keep a card out of the release notes. Defaults to "internal".
+	Hide []string `json:"hide"`
+
+	// Template is the path to a Go text/template that renders the release notes.
+	Template string `json:"template"`
+}
+
+// ReleaseSectionConfig maps a set of labels to a section of the release notes.
+type ReleaseSectionConfig struct {
+	Title  string   `json:"title"`
+	Labels []string `json:"labels"`
+}
+
+// ReleaseNotes are the data available to a release notes template.
+type ReleaseNotes struct {
+	Sprint   string
+	BoardURL string
+	Sections []ReleaseSection
+}
+
+// ReleaseSection is one titled group of entries.
+type ReleaseSection struct {
+	Title   string
+	Entries []ReleaseEntry
+}
+
+// ReleaseEntry is one completed card in the release notes.
+type ReleaseEntry struct {
+	Card
+	PullRequests []string
+}
+
+// buildReleaseNotes sorts a report's cards into sections and finds their pull requests.
+func buildReleaseNotes(conn Connection, config ReleaseNotesConfig, r *SprintReport) (*ReleaseNotes, error) {
+	hide := config.Hide
+	if hide == nil {
+		hide = []string{"internal"}
+	}
+
+	other := config.Other
+	if other == "" {
+		other = "Other"
+	}
+
+	sections := make([]ReleaseSection, len(config.Sections))
+	for i, section := range config.Sections {
+		sections[i].Title = section.Title
+	}
+	var leftover []ReleaseEntry
+
+	for _, card := range r.Cards {
+		labels := r.Labels(card)
+		if matchesLabel(labels, hide) {
+			log.WithField("card name", card.Name).Debug("Hiding card from release notes")
+			continue
+		}
+
+		attachments, err := conn.GetAttachments(card.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := ReleaseEntry{Card: card}
+		for _, attachment := range attachments {
+			if pullRequestPattern.MatchString(attachment.URL) {
+				entry.PullRequests = append(entry.PullRequests, attachment.URL)
+			}
+		}
+
+		placed := false
+		for i, section := range config.Sections {
+			if matchesLabel(labels, section.Labels) {
+				sections[i].Entries = append(sections[i].Entries, entry)
+				placed = true
+				break
+			}
+		}
+
+		if !placed {
+			leftover = append(leftover, entry)
+		}
+	}
+
+	if other != "-" && len(leftover) > 0 {
+		sections = append(sections, ReleaseSection{Title: other, Entries: leftover})
+	}
+
+	notes := &ReleaseNotes{Sprint: r.Name, BoardURL: r.BoardURL}
+	for _, section := range sections {
+		if len(section.Entries) > 0 {
+			notes.Sections = append(notes.Sections, section)
+		}
+	}
+
+	return notes, nil
+}
+
+// matchesLabel returns true if any label appears in candidates, ignoring case.
+func matchesLabel(labels, candidates []string) bool {
+	for _, label := range labels {
+		for _, candidate := range candidates {
+			if strings.EqualFold(label, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadReleaseNotesTemplate parses the template at path, or the default template if path is empty.
+func loadReleaseNotesTemplate(path string) (*template.Template, error) {
+	source := defaultReleaseNotesTemplate
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source = string(b)
+	}
+
+	return template.New("release-notes").Funcs(template.FuncMap{
+		"excerpt": excerpt,
+		"join":    strings.Join,
+	}).Parse(source)
+}
+
+func releaseNotes(c *cli.Context) {
+	p, conn := setup(c)
+
+	templatePath := c.String("template")
+	if templatePath == "" {
+		templatePath = p.ReleaseNotes.Template
+	}
+
+	tmpl, err := loadReleaseNotesTemplate(templatePath)
+	handleErr(err)
+
+	board, err := findArchiveBoard(conn, c.Args().First())
+	handleErr(err)
+
+	doneList, err := conn.FindList(p.DoneList, board.ID)
+	handleErr(err)
+
+	report, err := buildSprintReport(conn, board, board.Name, doneList.ID, nil, sprintLabelFor(board.Name))
+	handleErr(err)
+
+	notes, err := buildReleaseNotes(conn, p.ReleaseNotes, report)
+	handleErr(err)
+
+	out := io.Writer(os.Stdout)
+	if path := c.String("output"); path != "" {
+		outf, err := os.Create(path)
+		handleErr(err)
+		defer outf.Close()
+		out = outf
+	}
+
+	err = tmpl.Execute(out, notes)
+	handleErr(err)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+const (
+	// unlabeledGroup collects the cards in a report that have no labels of their own.
+	unlabeledGroup = "Unlabeled"
+
+	// reportListName is the archive board list that holds a posted report card.
+	reportListName = "Sprint Report"
+
+	// reportListPosition places the report list ahead of the archived lists, which start at 1.
+	reportListPosition = 0.5
+
+	// maxDescription is the longest description Trello accepts on a board or card.
+	maxDescription = 16384
+
+	excerptLength = 140
+)
+
+// ReportConfig controls the sprint report that is generated at close time.
+type ReportConfig struct {
+	// Directory is where report files are written. Defaults to the working directory.
+	Directory string `json:"directory"`
+
+	// Post, if set, also publishes the report on the archive board. It may be "card" or "description".
+	Post string `json:"post"`
+}
+
+// SprintReport summarizes the work completed during a sprint.
+type SprintReport struct {
+	Name     string
+	BoardURL string
+	Cards    []Card
+
+	// IgnoreLabels are not used to group cards, like the sprint label that every card carries.
+	IgnoreLabels []string
+
+	// Points reads story points from the report's cards.
+	Points *PointsReader
+
+	// Flow holds cycle and lead time percentiles, if they were measured.
+	Flow []FlowSummary
+}
+
+// LabelGroup is the set of completed cards that share a label.
+type LabelGroup struct {
+	Label string
+	Cards []Card
+}
+
+// buildSprintReport collects the completed cards from the done list of an archive board.
+func buildSprintReport(conn Connection, board *Board, name, doneListID string, points *PointsReader, ignoreLabels ...string) (*SprintReport, error) {
+	cards, err := conn.GetCards(doneListID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SprintReport{
+		Name:         name,
+		BoardURL:     board.URL,
+		Cards:        cards,
+		IgnoreLabels: ignoreLabels,
+		Points:       points,
+	}, nil
+}
+
+// CardPoints returns the story points of one card in the report.
+func (r *SprintReport) CardPoints(card Card) float64 {
+	return r.Points.Points(card)
+}
+
+// TotalPoints adds up the story points of every card in the report.
+func (r *SprintReport) TotalPoints() float64 {
+	return r.sumPoints(r.Cards)
+}
+
+// GroupPoints adds up the story points of the cards that share a label.
+func (r *SprintReport) GroupPoints(g LabelGroup) float64 {
+	return r.sumPoints(g.Cards)
+}
+
+func (r *SprintReport) sumPoints(cards []Card) float64 {
+	var total float64
+	for _, card := range cards {
+		total += r.CardPoints(card)
+	}
+	return total
+}
+
+// Groups sorts the report's cards by label name. Cards with several labels appear in each group.
+// Unlabeled cards are collected last.
+func (r *SprintReport) Groups() []LabelGroup {
+	byLabel := make(map[string][]Card)
+	var names []string
+	var unlabeled []Card
+
+	for _, card := range r.Cards {
+		labels := r.Labels(card)
+		if len(labels) == 0 {
+			unlabeled = append(unlabeled, card)
+			continue
+		}
+
+		for _, label := range labels {
+			if _, ok := byLabel[label]; !ok {
+				names = append(names, label)
+			}
+			byLabel[label] = append(byLabel[label], card)
+		}
+	}
+
+	sort.Strings(names)
+
+	groups := make([]LabelGroup, 0, len(names)+1)
+	for _, name := range names {
+		groups = append(groups, LabelGroup{Label: name, Cards: byLabel[name]})
+	}
+	if len(unlabeled) > 0 {
+		groups = append(groups, LabelGroup{Label: unlabeledGroup, Cards: unlabeled})
+	}
+
+	return groups
+}
+
+// Labels returns the names of a card's labels, skipping any that the report ignores.
+func (r *SprintReport) Labels(card Card) []string {
+	var labels []string
+	for _, label := range card.Labels {
+		name := label.Name
+		if name == "" {
+			name = label.Color
+		}
+		if name == "" || containsString(r.IgnoreLabels, name) {
+			continue
+		}
+		labels = append(labels, name)
+	}
+	return labels
+}
+
+// writeReport saves a Markdown report to a file named after the sprint and returns its path.
+func writeReport(dir, name string, r *SprintReport) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	path := filepath.Join(dir, name+".md")
+	outf, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := (MarkdownFormatter{}).Format(outf, r); err != nil {
+		outf.Close()
+		return "", err
+	}
+
+	return path, outf.Close()
+}
+
+// validate checks the report settings that can be checked without touching Trello.
+func (config ReportConfig) validate() error {
+	switch config.Post {
+	case "", "card", "description":
+	default:
+		return fmt.Errorf("Unknown report post setting [%s]. Use \"card\" or \"description\".", config.Post)
+	}
+	return nil
+}
+
+// checkDirectory makes sure that files can be written to dir, or the working directory if it's empty.
+func checkDirectory(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("Unable to use the directory [%s]: %v", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("[%s] is not a directory.", dir)
+	}
+	return nil
+}
+
+// postReport publishes a rendered report on the archive board as configured.
+func postReport(conn Connection, config ReportConfig, boardID string, r *SprintReport) error {
+	if config.Post == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := (MarkdownFormatter{}).Format(&buf, r); err != nil {
+		return err
+	}
+	body := truncate(buf.String(), maxDescription)
+
+	switch config.Post {
+	case "description":
+		return conn.SetBoardDescription(boardID, body)
+	case "card":
+		listID, err := conn.AddList(reportListName, boardID, reportListPosition)
+		if err != nil {
+			return err
+		}
+		_, err = conn.AddCard(listID, r.Name, body)
+		return err
+	default:
+		return fmt.Errorf("Unknown report post setting [%s]. Use \"card\" or \"description\".", config.Post)
+	}
+}
+
+// excerpt shortens a card description to its first line, up to excerptLength characters.
+func excerpt(desc string) string {
+	desc = strings.TrimSpace(desc)
+	if i := strings.IndexAny(desc, "\r\n"); i != -1 {
+		desc = desc[:i]
+	}
+	return truncate(desc, excerptLength)
+}
+
+// truncate shortens s to at most max characters, marking where it was cut.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(s)
+}
+
+// showReport prints the report for an archived sprint in the requested format.
+func showReport(c *cli.Context) {
+	p, conn := setup(c)
+
+	formatter, err := FindFormatter(c.String("format"))
+	handleErr(err)
+
+	inProgress, err := p.Flow.inProgressRefs()
+	handleErr(err)
+
+	board, err := findArchiveBoard(conn, c.Args().First())
+	handleErr(err)
+
+	log.WithFields(log.Fields{
+		"board id":   board.ID,
+		"board name": board.Name,
+	}).Debug("Archive board located.")
+
+	doneList, err := conn.FindList(p.DoneList, board.ID)
+	handleErr(err)
+
+	points, err := newPointsReader(conn, p.Points, board.ID)
+	handleErr(err)
+
+	report, err := buildSprintReport(conn, board, board.Name, doneList.ID, points, sprintLabelFor(board.Name))
+	handleErr(err)
+
+	if c.Bool("flow") {
+		end, ok := sprintEndFor(board.Name)
+		if !ok {
+			end = time.Now()
+		}
+
+		err = addFlow(conn, p, inProgress, report, end)
+		handleErr(err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if path := c.String("output"); path != "" {
+		outf, err := os.Create(path)
+		handleErr(err)
+		defer outf.Close()
+		out = outf
+	}
+
+	err = formatter.Format(out, report)
+	handleErr(err)
+}
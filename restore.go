@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// BoardExport is the part of a backed up board that can be restored.
+type BoardExport struct {
+	Name       string       `json:"name"`
+	Labels     []Label      `json:"labels"`
+	Lists      []List       `json:"lists"`
+	Cards      []ExportCard `json:"cards"`
+	Checklists []Checklist  `json:"checklists"`
+}
+
+// ExportCard is a card as it appears in a board backup.
+type ExportCard struct {
+	Card
+	Position float64 `json:"pos"`
+	Due      string  `json:"due"`
+}
+
+type byCardPosition []ExportCard
+
+func (s byCardPosition) Len() int           { return len(s) }
+func (s byCardPosition) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byCardPosition) Less(i, j int) bool { return s[i].Position < s[j].Position }
+
+type byChecklistPosition []Checklist
+
+func (s byChecklistPosition) Len() int           { return len(s) }
+func (s byChecklistPosition) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byChecklistPosition) Less(i, j int) bool { return s[i].Position < s[j].Position }
+
+type byCheckItemPosition []CheckItem
+
+func (s byCheckItemPosition) Len() int           { return len(s) }
+func (s byCheckItemPosition) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byCheckItemPosition) Less(i, j int) bool { return s[i].Position < s[j].Position }
+
+// loadBackup reads a backup file written by writeBackup.
+func loadBackup(path string) (*Backup, *BoardExport, error) {
+	inf, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer inf.Close()
+
+	var b Backup
+	if err := json.NewDecoder(inf).Decode(&b); err != nil {
+		return nil, nil, fmt.Errorf("Unable to read the backup [%s]: %v", path, err)
+	}
+
+	if len(b.Board) == 0 {
+		return nil, nil, fmt.Errorf("The file [%s] does not contain a board backup.", path)
+	}
+
+	var export BoardExport
+	if err := json.Unmarshal(b.Board, &export); err != nil {
+		return nil, nil, fmt.Errorf("Unable to read the board in [%s]: %v", path, err)
+	}
+
+	return &b, &export, nil
+}
+
+// restoredID records where an object from a backup lives on the target board.
+type restoredID struct {
+	Kind    string
+	Name    string
+	OldID   string
+	NewID   string
+	Created bool
+}
+
+// restorePlan compares a backup with the target board. Open labels, lists and cards that are
+// already on the target board, matched by name, are kept; the rest are created.
+type restorePlan struct {
+	export  *BoardExport
+	boardID string
+
+	ids     map[string]string
+	matched []restoredID
+
+	labels []Label
+	lists  []List
+	cards  []ExportCard
+}
+
+// planRestore matches the contents of a backup against the current state of a board.
+func planRestore(conn Connection, export *BoardExport, boardID string) (*restorePlan, error) {
+	plan := &restorePlan{export: export, boardID: boardID, ids: make(map[string]string)}
+
+	labels, err := conn.GetLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, label := range export.Labels {
+		found := false
+		for _, existing := range labels {
+			if existing.Name == label.Name && existing.Color == label.Color {
+				plan.keep("label", label.Name, label.ID, existing.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			plan.labels = append(plan.labels, label)
+		}
+	}
+
+	lists, err := conn.GetLists(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	restoredLists := make(map[string]bool)
+	for _, list := range export.Lists {
+		if list.Closed {
+			continue
+		}
+		restoredLists[list.ID] = true
+
+		found := false
+		for _, existing := range lists {
+			if !existing.Closed && existing.Name == list.Name {
+				plan.keep("list", list.Name, list.ID, existing.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			plan.lists = append(plan.lists, list)
+		}
+	}
+
+	cards, err := conn.GetBoardCards(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range export.Cards {
+		if card.Closed || !restoredLists[card.ListID] {
+			continue
+		}
+
+		found := false
+		if listID, ok := plan.ids[card.ListID]; ok {
+			for _, existing := range cards {
+				if existing.ListID == listID && existing.Name == card.Name {
+					plan.keep("card", card.Name, card.ID, existing.ID)
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			plan.cards = append(plan.cards, card)
+		}
+	}
+	sort.Stable(byCardPosition(plan.cards))
+
+	return plan, nil
+}
+
+func (plan *restorePlan) keep(kind, name, oldID, newID string) {
+	plan.ids[oldID] = newID
+	plan.matched = append(plan.matched, restoredID{Kind: kind, Name: name, OldID: oldID, NewID: newID})
+}
+
+// listName returns the name of a list in the backup.
+func (plan *restorePlan) listName(listID string) string {
+	for _, list := range plan.export.Lists {
+		if list.ID == listID {
+			return list.Name
+		}
+	}
+	return listID
+}
+
+// checklists returns the backed up checklists of a card, in order.
+func (plan *restorePlan) checklists(cardID string) []Checklist {
+	var results []Checklist
+	for _, checklist := range plan.export.Checklists {
+		if checklist.CardID == cardID {
+			results = append(results, checklist)
+		}
+	}
+	sort.Stable(byChecklistPosition(results))
+	return results
+}
+
+// printDiff describes what applying the plan would create.
+func (plan *restorePlan) printDiff(out io.Writer) {
+	for _, label := range plan.labels {
+		fmt.Fprintf(out, "+ label %q (%s)\n", label.Name, label.Color)
+	}
+	for _, list := range plan.lists {
+		fmt.Fprintf(out, "+ list  %q\n", list.Name)
+	}
+	for _, card := range plan.cards {
+		checklists := plan.checklists(card.ID)
+		fmt.Fprintf(out, "+ card  %q in %q (%d labels, %d members, %d checklists)\n",
+			card.Name, plan.listName(card.ListID), len(card.LabelIDs), len(card.MemberIDs), len(checklists))
+	}
+
+	fmt.Fprintf(out, "%d labels, %d lists and %d cards to create. %d objects are already on the board.\n",
+		len(plan.labels), len(plan.lists), len(plan.cards), len(plan.matched))
+}
+
+// apply creates everything that is missing from the target board. It returns where each object in
+// the backup ended up, including those that were already there.
+func (plan *restorePlan) apply(conn Connection) ([]restoredID, error) {
+	results := append([]restoredID(nil), plan.matched...)
+	created := func(kind, name, oldID, newID string) {
+		plan.ids[oldID] = newID
+		results = append(results, restoredID{Kind: kind, Name: name, OldID: oldID, NewID: newID, Created: true})
+	}
+
+	for _, label := range plan.labels {
+		id, err := conn.CreateLabel(plan.boardID, label.Name, label.Color)
+		if err != nil {
+			return results, err
+		}
+		created("label", label.Name, label.ID, id)
+	}
+
+	for _, list := range plan.lists {
+		id, err := conn.AddList(list.Name, plan.boardID, list.Position)
+		if err != nil {
+			return results, err
+		}
+		created("list", list.Name, list.ID, id)
+	}
+
+	// Adding someone who is already on the board would change their role, which could demote an admin.
+	myID, err := conn.FindMyUserID()
+	if err != nil {
+		return results, err
+	}
+
+	memberships, err := conn.GetBoardMemberships(plan.boardID)
+	if err != nil {
+		return results, err
+	}
+
+	members := map[string]bool{myID: true}
+	for _, membership := range memberships {
+		members[membership.MemberID] = true
+	}
+
+	for _, card := range plan.cards {
+		for _, memberID := range card.MemberIDs {
+			if !members[memberID] {
+				log.WithField("member id", memberID).Debug("Granting access")
+				if err := conn.AddMember(plan.boardID, memberID); err != nil {
+					return results, err
+				}
+				members[memberID] = true
+			}
+		}
+	}
+
+	for _, card := range plan.cards {
+		restored := card.Card
+		restored.LabelIDs = nil
+		for _, labelID := range card.LabelIDs {
+			if id, ok := plan.ids[labelID]; ok {
+				restored.LabelIDs = append(restored.LabelIDs, id)
+			}
+		}
+
+		id, err := conn.RestoreCard(plan.ids[card.ListID], restored, card.Position, card.Due)
+		if err != nil {
+			return results, err
+		}
+		created("card", card.Name, card.ID, id)
+
+		for _, checklist := range plan.checklists(card.ID) {
+			checklistID, err := conn.AddChecklist(id, checklist.Name, checklist.Position)
+			if err != nil {
+				return results, err
+			}
+			created("checklist", checklist.Name, checklist.ID, checklistID)
+
+			items := append([]CheckItem(nil), checklist.CheckItems...)
+			sort.Stable(byCheckItemPosition(items))
+
+			for _, item := range items {
+				itemID, err := conn.AddCheckItem(checklistID, item.Name, item.State == "complete")
+				if err != nil {
+					return results, err
+				}
+				created("check item", item.Name, item.ID, itemID)
+			}
+		}
+
+		log.WithField("card name", card.Name).Debug("Restored card")
+	}
+
+	return results, nil
+}
+
+// printIDs lists where each object in the backup ended up.
+func printIDs(out io.Writer, ids []restoredID) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tOLD ID\tNEW ID\tSTATUS\tNAME")
+	for _, id := range ids {
+		status := "existing"
+		if id.Created {
+			status = "created"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", id.Kind, id.OldID, id.NewID, status, id.Name)
+	}
+	w.Flush()
+}
+
+func restore(c *cli.Context) {
+	p, conn := setup(c)
+
+	path := c.Args().First()
+	if path == "" {
+		handleErr(errors.New("Give the path to a backup file, like sprint-backup-2026-10-16T170512.json."))
+	}
+
+	backup, export, err := loadBackup(path)
+	handleErr(err)
+
+	log.WithFields(log.Fields{
+		"board name": export.Name,
+		"taken":      backup.Taken,
+	}).Info("Loaded the backup.")
+
+	boardRef := c.String("board")
+	if boardRef == "" {
+		boardRef = p.SprintBoard
+	}
+
+	boardID, err := conn.FindBoard(boardRef)
+	handleErr(err)
+
+	plan, err := planRestore(conn, export, boardID)
+	handleErr(err)
+
+	plan.printDiff(os.Stdout)
+
+	if c.Bool("dry-run") {
+		return
+	}
+
+	ids, err := plan.apply(conn)
+	printIDs(os.Stdout, ids)
+	handleErr(err)
+}
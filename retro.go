@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// defaultRetroLists are the lists of a retrospective, in order.
+var defaultRetroLists = []string{"Went well", "To improve", "Action items"}
+
+// RetroConfig controls the retrospective area that is created at close time.
+type RetroConfig struct {
+	// Mode is "lists" to add the retrospective lists to the archive board, or "board" to create a
+	// separate board like "DevEx Retro 2026-10-16". No retrospective is created if it is empty.
+	Mode string `json:"mode"`
+
+	// Lists names the retrospective lists, in order. Defaults to "Went well", "To improve" and
+	// "Action items".
+	Lists []string `json:"lists"`
+
+	// ActionItems, if set, identifies a list on the sprint board. Open cards in the last list of the
+	// previous sprint's retrospective are copied there during the close.
+	ActionItems string `json:"action_items"`
+}
+
+// Retro describes the retrospective area created for a sprint.
+type Retro struct {
+	BoardID string
+	ListIDs []string
+}
+
+// validate checks the retrospective mode before anything is changed.
+func (config RetroConfig) validate() error {
+	switch config.Mode {
+	case "", "lists", "board":
+		return nil
+	default:
+		return fmt.Errorf("Unknown retro mode [%s]. Use \"lists\" or \"board\".", config.Mode)
+	}
+}
+
+// scaffoldRetro creates the retrospective lists, either on the archive board starting at position
+// or on a new board shared with the organization.
+func scaffoldRetro(conn Connection, config RetroConfig, archiveBoardID string, position int, org *Org, myID string) (*Retro, error) {
+	lists := config.Lists
+	if len(lists) == 0 {
+		lists = defaultRetroLists
+	}
+
+	retro := &Retro{}
+
+	switch config.Mode {
+	case "":
+		return nil, nil
+	case "lists":
+		retro.BoardID = archiveBoardID
+	case "board":
+		name := retroBoardName()
+		board, err := conn.CreateBoard(name)
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithFields(log.Fields{
+			"board id":   board.ID,
+			"board name": name,
+		}).Info("Created retrospective board.")
+
+		if err := grantAccess(conn, board.ID, org, myID); err != nil {
+			return nil, err
+		}
+
+		if err := clearLists(conn, board.ID); err != nil {
+			return nil, err
+		}
+
+		retro.BoardID = board.ID
+		position = 1
+	default:
+		return nil, config.validate()
+	}
+
+	for i, name := range lists {
+		listID, err := conn.AddList(name, retro.BoardID, float64(position+i))
+		if err != nil {
+			return nil, err
+		}
+		retro.ListIDs = append(retro.ListIDs, listID)
+	}
+
+	return retro, nil
+}
+
+// actionItemPlan holds the retrospective action items that are copied into the new sprint, located
+// before anything is changed.
+type actionItemPlan struct {
+	target *List
+	cards  []Card
+}
+
+// planActionItems finds the open cards in the last list of the previous sprint's retrospective and
+// the list on the sprint board that they are copied to. The retrospective may be on its own board or
+// on the archive board.
+func planActionItems(conn Connection, config RetroConfig, boardID string) (*actionItemPlan, error) {
+	plan := &actionItemPlan{}
+	if config.ActionItems == "" {
+		return plan, nil
+	}
+
+	target, err := conn.FindList(config.ActionItems, boardID)
+	if err != nil {
+		return nil, err
+	}
+	plan.target = target
+
+	log.WithField("list id", target.ID).Debug("Action item list located.")
+
+	lists := config.Lists
+	if len(lists) == 0 {
+		lists = defaultRetroLists
+	}
+	r, err := ParseRef(lists[len(lists)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	sprints, err := findArchivedSprints(conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(sprints) == 0 {
+		return plan, nil
+	}
+	previous := sprints[len(sprints)-1]
+
+	boards, err := conn.ListBoards()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, board := range boards {
+		if board.Name == retroBoardFor(previous.End) {
+			candidates = append(candidates, board.ID)
+		}
+	}
+	candidates = append(candidates, previous.ID)
+
+	for _, candidate := range candidates {
+		retroLists, err := conn.GetLists(candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, list := range retroLists {
+			if list.Closed || !r.Matches(list.ID, "", list.Name) {
+				continue
+			}
+
+			cards, err := conn.GetCards(list.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			log.WithFields(log.Fields{
+				"board id":   candidate,
+				"card count": len(cards),
+			}).Debug("Previous action items located.")
+
+			plan.cards = cards
+			return plan, nil
+		}
+	}
+
+	return plan, nil
+}
+
+// execute copies each action item to the target list with a link back to the original card. It
+// returns the number of cards copied.
+func (plan *actionItemPlan) execute(conn Connection) (int, error) {
+	if plan.target == nil {
+		return 0, nil
+	}
+
+	for _, card := range plan.cards {
+		desc := fmt.Sprintf("Carried forward from the retrospective: %s", card.ShortURL)
+		if card.Desc != "" {
+			desc = card.Desc + "\n\n" + desc
+		}
+
+		if _, err := conn.AddCard(plan.target.ID, card.Name, desc); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(plan.cards), nil
+}
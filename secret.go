@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// hasTokenSource returns true if the profile says where to find its token.
+func hasTokenSource(p *Profile) bool {
+	return p.TokenCommand != "" || p.TokenFile != "" || len(p.TokenKeyring) > 0
+}
+
+// resolveToken fills in the profile's token from its token_command, token_file or token_keyring.
+// A token given directly, for example in TRELLO_TOKEN, takes precedence over all of them.
+func resolveToken(p *Profile) error {
+	sources := 0
+	for _, set := range []bool{p.TokenCommand != "", p.TokenFile != "", len(p.TokenKeyring) > 0} {
+		if set {
+			sources++
+		}
+	}
+
+	if sources == 0 {
+		return nil
+	}
+
+	if p.Token != "" {
+		log.Warn("A token is set directly, in the profile or TRELLO_TOKEN, so token_command, token_file " +
+			"and token_keyring are ignored. Remove it to use them.")
+		return nil
+	}
+
+	if sources > 1 {
+		return errors.New("Use only one of token_command, token_file and token_keyring.")
+	}
+
+	var token string
+	var err error
+	switch {
+	case p.TokenCommand != "":
+		token, err = tokenFromCommand(p.TokenCommand)
+		p.tokenSource = "token_command"
+	case p.TokenFile != "":
+		token, err = tokenFromFile(p.TokenFile)
+		p.tokenSource = "token_file"
+	default:
+		token, err = tokenFromKeyring(p.TokenKeyring)
+		p.tokenSource = "token_keyring"
+	}
+	if err != nil {
+		return err
+	}
+
+	if token == "" {
+		return fmt.Errorf("The %s produced an empty token.", p.tokenSource)
+	}
+
+	p.Token = token
+	return nil
+}
+
+// tokenFromCommand runs a shell command and returns the first line that it prints.
+func tokenFromCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("The token_command [%s] failed: %v %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return firstLine(strings.TrimSpace(string(out))), nil
+}
+
+// tokenFromFile reads a token from a file, warning if anyone else can read it.
+func tokenFromFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		path = filepath.Join(os.Getenv("HOME"), path[2:])
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0044 != 0 {
+		log.WithField("path", path).Warnf("The token file can be read by others. Run: chmod 600 %s", path)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read the token_file: %v", err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// tokenFromKeyring looks up a secret by its attributes in the desktop keyring over the D-Bus Secret
+// Service API, using libsecret's secret-tool. Store the token with, for example:
+//
+//	secret-tool store --label "Trello token" service trello account me
+func tokenFromKeyring(attributes map[string]string) (string, error) {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := []string{"lookup"}
+	for _, key := range keys {
+		args = append(args, key, attributes[key])
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return "", errors.New("Reading the token_keyring needs secret-tool. Install libsecret-tools or your platform's equivalent.")
+		}
+		return "", fmt.Errorf("Unable to find the token_keyring secret [%s]: %v %s",
+			strings.Join(args[1:], " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
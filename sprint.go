@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// sprintEnd returns the most recent Friday, which is the last day of the sprint being closed.
+func sprintEnd() time.Time {
+	n := time.Now()
+
+	daysUntilFriday := (time.Friday - n.Weekday() - 7) % 7
+	return n.AddDate(0, 0, int(daysUntilFriday))
+}
+
+var (
+	// sprintDatePattern recognizes a sprint given by the date that it ended.
+	sprintDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+	// archiveBoardPattern recognizes the archive boards created by newBoardName.
+	archiveBoardPattern = regexp.MustCompile(`^DevEx Sprint (\d{4}-\d{2}-\d{2})$`)
+)
+
+// sprintEndFor parses the date that a sprint ended from the name of its archive board.
+func sprintEndFor(boardName string) (time.Time, bool) {
+	m := archiveBoardPattern.FindStringSubmatch(boardName)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	end, err := time.ParseInLocation("2006-01-02", m[1], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+// ArchivedSprint is an archive board created by a previous close.
+type ArchivedSprint struct {
+	Board
+	End time.Time
+}
+
+type byEnd []ArchivedSprint
+
+func (s byEnd) Len() int           { return len(s) }
+func (s byEnd) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byEnd) Less(i, j int) bool { return s[i].End.Before(s[j].End) }
+
+// findArchivedSprints lists the organization's archive boards, open or closed, oldest first.
+func findArchivedSprints(conn Connection) ([]ArchivedSprint, error) {
+	boards, err := conn.ListBoards()
+	if err != nil {
+		return nil, err
+	}
+
+	var sprints []ArchivedSprint
+	for _, board := range boards {
+		if end, ok := sprintEndFor(board.Name); ok {
+			sprints = append(sprints, ArchivedSprint{Board: board, End: end})
+		}
+	}
+
+	sort.Sort(byEnd(sprints))
+	return sprints, nil
+}
+
+func newBoardName() string {
+	return boardNameFor(sprintEnd())
+}
+
+// retroBoardName returns the name of the separate retrospective board for the sprint being closed.
+func retroBoardName() string {
+	return retroBoardFor(sprintEnd())
+}
+
+// retroBoardFor returns the name of the separate retrospective board for the sprint ending on end.
+func retroBoardFor(end time.Time) string {
+	return fmt.Sprintf("DevEx Retro %s", end.Format("2006-01-02"))
+}
+
+// boardNameFor returns the name of the archive board for the sprint that ended on a given day.
+func boardNameFor(end time.Time) string {
+	return fmt.Sprintf("DevEx Sprint %s", end.Format("2006-01-02"))
+}
+
+// findArchiveBoard locates the archive board for a sprint given on the command line, whether or not
+// the board has been closed. A date like "2026-10-16" is turned into the name of that sprint's
+// archive board; anything else is treated as a board reference. With no sprint at all, the most
+// recently closed sprint is used.
+func findArchiveBoard(conn Connection, sprint string) (*Board, error) {
+	if sprint == "" {
+		sprints, err := findArchivedSprints(conn)
+		if err != nil {
+			return nil, err
+		}
+		if len(sprints) == 0 {
+			return nil, errors.New("No sprints have been archived yet.")
+		}
+		return &sprints[len(sprints)-1].Board, nil
+	}
+
+	if sprintDatePattern.MatchString(sprint) {
+		sprint = "DevEx Sprint " + sprint
+	}
+
+	r, err := ParseRef(sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	boards, err := conn.ListBoards()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Board
+	for _, board := range boards {
+		if r.Matches(board.ID, board.ShortLink, board.Name) {
+			matches = append(matches, board)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("Unable to find a board matching [%s].", sprint)
+	case 1:
+		return &matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, board := range matches {
+			names = append(names, fmt.Sprintf("%s (%s)", board.Name, board.ID))
+		}
+		return nil, ambiguousRefError("board", r, names)
+	}
+}
+
+// sprintLabelName is the label applied to each card completed during the sprint being closed.
+func sprintLabelName() string {
+	return fmt.Sprintf("Sprint %s", sprintEnd().Format("2006-01-02"))
+}
+
+// sprintLabelFor derives the sprint label from the name of an archive board.
+func sprintLabelFor(boardName string) string {
+	return strings.Replace(boardName, "DevEx Sprint", "Sprint", 1)
+}
+
+// findDoneList picks the archived list that holds completed work.
+func findDoneList(ref string, archiveLists []*List) (*List, error) {
+	r, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, list := range archiveLists {
+		if r.Matches(list.ID, "", list.Name) {
+			return list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("The done list [%s] must be one of the archive lists.", ref)
+}
+
+// labelSprintCards applies a label named after the sprint to every card on a list. It returns the
+// number of cards that were labeled.
+func labelSprintCards(conn Connection, boardID, listID, labelName string) (int, error) {
+	labelID, err := conn.EnsureLabel(boardID, labelName, "green")
+	if err != nil {
+		return 0, err
+	}
+
+	cards, err := conn.GetCards(listID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, card := range cards {
+		if containsString(card.LabelIDs, labelID) {
+			continue
+		}
+
+		log.WithField("card id", card.ID).Debug("Applying sprint label")
+		if err := conn.AddLabel(card.ID, labelID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(cards), nil
+}
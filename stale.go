@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/codegangsta/cli"
+)
+
+// staleCard is an unfinished card along with the number of sprint closes it has survived.
+type staleCard struct {
+	Card
+	Carried int
+}
+
+type byCarried []staleCard
+
+func (s byCarried) Len() int           { return len(s) }
+func (s byCarried) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byCarried) Less(i, j int) bool { return s[i].Carried > s[j].Carried }
+
+func stale(c *cli.Context) {
+	p, conn := setup(c)
+	threshold := c.Int("threshold")
+
+	boardID, err := conn.FindBoard(p.SprintBoard)
+	handleErr(err)
+
+	counterField := p.CarryOver.CounterField
+	if counterField == "" {
+		counterField = defaultCounterField
+	}
+
+	fieldID, err := conn.FindNumberField(boardID, counterField)
+	handleErr(err)
+
+	if fieldID == "" {
+		handleErr(fmt.Errorf("The board has no [%s] custom field. Has a sprint been closed with carry_over.counter_field set?",
+			counterField))
+	}
+
+	cards, err := conn.GetBoardCards(boardID)
+	handleErr(err)
+
+	var results []staleCard
+	for _, card := range cards {
+		carried := int(card.NumberField(fieldID))
+		if carried >= threshold {
+			results = append(results, staleCard{Card: card, Carried: carried})
+		}
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No cards have been carried over %d or more times.\n", threshold)
+		return
+	}
+
+	sort.Stable(byCarried(results))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CARRIED\tAGE\tCARD\tOWNERS\tLINK")
+	for _, result := range results {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			result.Carried, age(result.Created()), result.Name, owners(result.Members), result.ShortURL)
+	}
+	w.Flush()
+}
+
+// age describes how long ago a moment was, in days or weeks.
+func age(t time.Time) string {
+	days := int(time.Since(t).Hours() / 24)
+	if days < 14 {
+		return fmt.Sprintf("%dd", days)
+	}
+	return fmt.Sprintf("%dw", days/7)
+}
+
+// owners lists the usernames of the members assigned to a card.
+func owners(members []Member) string {
+	if len(members) == 0 {
+		return "(nobody)"
+	}
+
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Username)
+	}
+	return strings.Join(names, ", ")
+}
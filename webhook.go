@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/smashwilson/sprint-closer/Godeps/_workspace/src/github.com/Sirupsen/logrus"
+)
+
+// defaultWebhookTemplate is the message text posted when no template is configured.
+const defaultWebhookTemplate = `Sprint closed: {{.Sprint}}. {{.CardsDone}} cards done{{if .Points}} for {{points .Points}} points{{end}}.
+{{with .Contributors}}Top contributors: {{range $i, $c := .}}{{if $i}}, {{end}}{{$c.DisplayName}} ({{$c.Cards}}){{end}}.
+{{end}}{{with .CarriedOver}}Carried over: {{range $i, $c := .}}{{if $i}}, {{end}}{{$c.Name}}{{end}}.
+{{end}}{{.BoardURL}}`
+
+// WebhookConfig describes an incoming webhook that is told about each close.
+type WebhookConfig struct {
+	// URL is the address that summaries are posted to.
+	URL string `json:"url"`
+
+	// Format is "slack" for a Slack-compatible payload, or "json" for the full summary. Defaults to
+	// "slack".
+	Format string `json:"format"`
+
+	// Template is a Go text/template that produces the message text from a SprintSummary.
+	Template string `json:"template"`
+
+	// Retries is the number of times a failed post is tried again. Defaults to 3.
+	Retries *int `json:"retries"`
+}
+
+// WebhookNotifier posts sprint summaries to an incoming webhook.
+type WebhookNotifier struct {
+	config     WebhookConfig
+	client     *http.Client
+	message    *template.Template
+	retries    int
+	retryDelay time.Duration
+}
+
+// NewWebhookNotifier prepares to post to a webhook. If client is nil, http.DefaultClient is used.
+func NewWebhookNotifier(config WebhookConfig, client *http.Client) (*WebhookNotifier, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	switch config.Format {
+	case "":
+		config.Format = "slack"
+	case "slack", "json":
+	default:
+		return nil, fmt.Errorf("Unknown webhook format [%s]. Use \"slack\" or \"json\".", config.Format)
+	}
+
+	source := config.Template
+	if source == "" {
+		source = defaultWebhookTemplate
+	}
+
+	message, err := template.New("webhook").Funcs(template.FuncMap{
+		"points": formatPoints,
+	}).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid webhook template: %v", err)
+	}
+
+	retries := 3
+	if config.Retries != nil {
+		retries = *config.Retries
+	}
+
+	return &WebhookNotifier{
+		config:     config,
+		client:     client,
+		message:    message,
+		retries:    retries,
+		retryDelay: time.Second,
+	}, nil
+}
+
+type webhookContributor struct {
+	Username string  `json:"username"`
+	FullName string  `json:"full_name"`
+	Cards    int     `json:"cards"`
+	Points   float64 `json:"points"`
+}
+
+type webhookCard struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type webhookPayload struct {
+	Text         string               `json:"text"`
+	Sprint       string               `json:"sprint"`
+	BoardURL     string               `json:"board_url"`
+	CardsDone    int                  `json:"cards_done"`
+	Points       float64              `json:"points"`
+	Contributors []webhookContributor `json:"contributors"`
+	CarriedOver  []webhookCard        `json:"carried_over"`
+}
+
+// payload renders the request body for a summary.
+func (n *WebhookNotifier) payload(s *SprintSummary) ([]byte, error) {
+	var text bytes.Buffer
+	if err := n.message.Execute(&text, s); err != nil {
+		return nil, err
+	}
+
+	if n.config.Format == "slack" {
+		return json.Marshal(map[string]string{"text": text.String()})
+	}
+
+	p := webhookPayload{
+		Text:         text.String(),
+		Sprint:       s.Sprint,
+		BoardURL:     s.BoardURL,
+		CardsDone:    s.CardsDone,
+		Points:       s.Points,
+		Contributors: make([]webhookContributor, 0, len(s.Contributors)),
+		CarriedOver:  make([]webhookCard, 0, len(s.CarriedOver)),
+	}
+	for _, c := range s.Contributors {
+		p.Contributors = append(p.Contributors, webhookContributor{
+			Username: c.Username,
+			FullName: c.FullName,
+			Cards:    c.Cards,
+			Points:   c.Points,
+		})
+	}
+	for _, card := range s.CarriedOver {
+		p.CarriedOver = append(p.CarriedOver, webhookCard{Name: card.Name, URL: card.ShortURL})
+	}
+
+	return json.Marshal(p)
+}
+
+// Notify implements Notifier. Network errors, rate limiting and server errors are retried with an
+// increasing delay; other failures are returned immediately.
+func (n *WebhookNotifier) Notify(s *SprintSummary) error {
+	body, err := n.payload(s)
+	if err != nil {
+		return err
+	}
+
+	delay := n.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(body)
+		if err == nil {
+			log.WithField("host", n.host()).Info("Posted the sprint summary to the webhook.")
+			return nil
+		}
+
+		if !retry || attempt >= n.retries {
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"attempt": attempt + 1,
+			"error":   err,
+		}).Warn("Webhook post failed. Retrying.")
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// host names the webhook's server without the rest of its URL, which is often a secret.
+func (n *WebhookNotifier) host() string {
+	u, err := url.Parse(n.config.URL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Host
+}
+
+// post sends one request. It reports whether a failure is worth retrying.
+func (n *WebhookNotifier) post(body []byte) (bool, error) {
+	resp, err := n.client.Post(n.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		if uerr, ok := err.(*url.Error); ok {
+			err = fmt.Errorf("%s %s: %v", uerr.Op, n.host(), uerr.Err)
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	rbody, _ := ioutil.ReadAll(resp.Body)
+	err = fmt.Errorf("Unexpected status code from webhook: %d\n%s", resp.StatusCode, strings.TrimSpace(string(rbody)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testSummary() *SprintSummary {
+	return &SprintSummary{
+		Sprint:    "DevEx Sprint 2026-10-16",
+		BoardURL:  "https://trello.com/b/AbCd1234",
+		CardsDone: 4,
+		Points:    7.5,
+		Contributors: []Contributor{
+			{Member: Member{ID: "m1", Username: "alice", FullName: "Alice"}, Cards: 3, Points: 5},
+		},
+		CarriedOver: []Card{{Name: "Slipped", ShortURL: "https://trello.com/c/XyZ"}},
+	}
+}
+
+// webhookStandIn records each request body and replies with the next status in statuses, then 200.
+func webhookStandIn(t *testing.T, statuses ...int) (*httptest.Server, *[]string) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+
+		bodies = append(bodies, string(b))
+		if len(bodies) <= len(statuses) {
+			w.WriteHeader(statuses[len(bodies)-1])
+		}
+	}))
+	return server, &bodies
+}
+
+func newTestNotifier(t *testing.T, config WebhookConfig, server *httptest.Server) *WebhookNotifier {
+	config.URL = server.URL + "/hooks/secret"
+	n, err := NewWebhookNotifier(config, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.retryDelay = 0
+	return n
+}
+
+func TestWebhookSlackPayload(t *testing.T) {
+	server, bodies := webhookStandIn(t)
+	defer server.Close()
+
+	n := newTestNotifier(t, WebhookConfig{}, server)
+	if err := n.Notify(testSummary()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*bodies) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*bodies))
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payload) != 1 {
+		t.Errorf("slack payload has extra fields: %v", payload)
+	}
+
+	for _, want := range []string{"DevEx Sprint 2026-10-16", "4 cards done for 7.5 points", "Alice (@alice) (3)", "Slipped"} {
+		if !strings.Contains(payload["text"], want) {
+			t.Errorf("text %q does not contain %q", payload["text"], want)
+		}
+	}
+}
+
+func TestWebhookJSONPayload(t *testing.T) {
+	server, bodies := webhookStandIn(t)
+	defer server.Close()
+
+	n := newTestNotifier(t, WebhookConfig{Format: "json", Template: "{{.Sprint}}"}, server)
+	if err := n.Notify(testSummary()); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal([]byte((*bodies)[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.Text != "DevEx Sprint 2026-10-16" || payload.CardsDone != 4 || payload.Points != 7.5 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+	if len(payload.Contributors) != 1 || payload.Contributors[0].Username != "alice" {
+		t.Errorf("unexpected contributors: %+v", payload.Contributors)
+	}
+	if len(payload.CarriedOver) != 1 || payload.CarriedOver[0].URL != "https://trello.com/c/XyZ" {
+		t.Errorf("unexpected carried over cards: %+v", payload.CarriedOver)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []int
+		retries  int
+		requests int
+		fails    bool
+	}{
+		{"server error", []int{http.StatusServiceUnavailable}, 3, 2, false},
+		{"rate limited", []int{http.StatusTooManyRequests, http.StatusBadGateway}, 3, 3, false},
+		{"client error", []int{http.StatusBadRequest}, 3, 1, true},
+		{"out of retries", []int{500, 500, 500}, 2, 3, true},
+	}
+
+	for _, tc := range cases {
+		server, bodies := webhookStandIn(t, tc.statuses...)
+
+		retries := tc.retries
+		n := newTestNotifier(t, WebhookConfig{Retries: &retries}, server)
+		err := n.Notify(testSummary())
+		server.Close()
+
+		if (err != nil) != tc.fails {
+			t.Errorf("%s: err = %v", tc.name, err)
+		}
+		if len(*bodies) != tc.requests {
+			t.Errorf("%s: got %d requests, want %d", tc.name, len(*bodies), tc.requests)
+		}
+	}
+}